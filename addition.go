@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"os"
 	"os/exec"
 	"runtime"
+	"time"
 )
 
+// processWaitDelay задаёт, сколько ждать закрытия вывода после отмены процесса,
+// если порождённые им процессы продолжают удерживать вывод.
+const processWaitDelay = 5 * time.Second
+
 // Sum вычисляет сумму элементов среза numbers типа T.
 //
 // @param numbers срез чисел типа T (int или float64)
@@ -19,11 +26,26 @@ func Sum[T ~int | ~float64](numbers []T) T {
 	return total
 }
 
+// newCommand создает процесс, который вместе с порождёнными им процессами завершается при отмене ctx.
+//
+// @param ctx контекст операции
+// @param name имя исполняемого файла
+// @param args аргументы
+// @return указатель на exec.Cmd
+func newCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = processWaitDelay
+	setProcessGroup(cmd)
+
+	return cmd
+}
+
 // runScriptLinux выполняет переданный скрипт shell в Linux/Unix-системах.
 //
+// @param ctx контекст операции, отмена которого завершает скрипт
 // @param script строка с shell-скриптом
 // @return вывод скрипта и ошибка (если есть)
-func runScriptLinux(script string) (string, error) {
+func runScriptLinux(ctx context.Context, script string) (string, error) {
 	tmp, err := os.CreateTemp("", "*.sh")
 	if err != nil {
 		return "", err
@@ -50,16 +72,17 @@ func runScriptLinux(script string) (string, error) {
 		return "", err
 	}
 
-	cmd := exec.Command(tmp.Name())
+	cmd := newCommand(ctx, tmp.Name())
 	out, err := cmd.CombinedOutput()
 	return string(out), err
 }
 
 // runScriptWindows выполняет переданный скрипт в Windows через .bat файл.
 //
+// @param ctx контекст операции, отмена которого завершает скрипт
 // @param script строка с bat-скриптом
 // @return вывод скрипта и ошибка (если есть)
-func runScriptWindows(script string) (string, error) {
+func runScriptWindows(ctx context.Context, script string) (string, error) {
 	tmp, err := os.CreateTemp("", "*.bat")
 	if err != nil {
 		return "", err
@@ -81,33 +104,35 @@ func runScriptWindows(script string) (string, error) {
 		return "", err
 	}
 
-	cmd := exec.Command("cmd", "/C", tmp.Name())
+	cmd := newCommand(ctx, "cmd", "/C", tmp.Name())
 	out, err := cmd.CombinedOutput()
 	return string(out), err
 }
 
 // runScript выполняет скрипт в зависимости от ОС (Windows или Linux).
 //
+// @param ctx контекст операции, отмена которого завершает скрипт
 // @param script строка скрипта
 // @return вывод скрипта и ошибка (если есть)
-func runScript(script string) (string, error) {
+func runScript(ctx context.Context, script string) (string, error) {
 	if runtime.GOOS == "windows" {
-		return runScriptWindows(script)
+		return runScriptWindows(ctx, script)
 	}
-	return runScriptLinux(script)
+	return runScriptLinux(ctx, script)
 }
 
 // runCommand выполняет команду cmdStr в командной строке ОС.
 //
+// @param ctx контекст операции, отмена которого завершает команду
 // @param cmdStr строка команды
 // @return вывод команды и ошибка (если есть)
-func runCommand(cmdStr string) (string, error) {
+func runCommand(ctx context.Context, cmdStr string) (string, error) {
 	var cmd *exec.Cmd
 
 	if runtime.GOOS == "windows" {
-		cmd = exec.Command("cmd", "/C", cmdStr)
+		cmd = newCommand(ctx, "cmd", "/C", cmdStr)
 	} else {
-		cmd = exec.Command("sh", "-c", cmdStr)
+		cmd = newCommand(ctx, "sh", "-c", cmdStr)
 	}
 
 	out, err := cmd.CombinedOutput()
@@ -115,6 +140,24 @@ func runCommand(cmdStr string) (string, error) {
 	return string(out), err
 }
 
+// exitCode возвращает код завершения процесса по ошибке его выполнения.
+//
+// @param err ошибка выполнения процесса
+// @return 0 при успехе, код завершения процесса или -1, если процесс не был запущен
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
+
 // reboot перезагружает компьютер в зависимости от ОС.
 //
 // @return ошибка, если перезагрузка не удалась
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff вычисляет задержки между повторными попытками по экспоненциальному
+// закону со случайным разбросом (jitter), чтобы клиенты не переподключались
+// к серверу одновременно.
+type Backoff struct {
+	Min     time.Duration // Минимальная задержка.
+	Max     time.Duration // Максимальная задержка.
+	attempt int           // Номер текущей попытки.
+}
+
+// NewBackoff создает новый экземпляр Backoff.
+//
+// @param min минимальная задержка
+// @param max максимальная задержка
+// @return указатель на Backoff
+func NewBackoff(min time.Duration, max time.Duration) *Backoff {
+	if min <= 0 {
+		min = time.Second
+	}
+
+	if max < min {
+		max = min
+	}
+
+	return &Backoff{Min: min, Max: max}
+}
+
+// Next возвращает задержку перед следующей попыткой и увеличивает счётчик попыток.
+// Задержка выбирается случайно в диапазоне [d/2, d], где d = Min * 2^attempt, но не больше Max.
+//
+// @return задержка перед следующей попыткой
+func (b *Backoff) Next() time.Duration {
+	delay := b.Min << b.attempt
+
+	if delay <= 0 || delay >= b.Max {
+		delay = b.Max
+	} else {
+		b.attempt++
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// Reset сбрасывает счётчик попыток к начальному значению.
+func (b *Backoff) Reset() {
+	b.attempt = 0
+}
@@ -12,14 +12,42 @@ type TypeSentMessage int
 
 // Константы для типов входящих сообщений.
 const (
-	StartContainer  TypeReceivedMessage = iota // Запуск контейнера
-	StopContainer                              // Остановка контейнера
-	RemoveContainer                            // Удаление контейнера
-	RemoveImage                                // Удаление образа
-	RunScript                                  // Выполнение скрипта
-	RunCommand                                 // Выполнение команды
-	Restart                                    // Перезапуск
-	Ok                                         // Подтверждение
+	StartContainer          TypeReceivedMessage = iota // Запуск контейнера
+	StopContainer                                      // Остановка контейнера
+	RemoveContainer                                    // Удаление контейнера
+	RemoveImage                                        // Удаление образа
+	RunScript                                          // Выполнение скрипта
+	RunCommand                                         // Выполнение команды
+	Restart                                            // Перезапуск
+	Ok                                                 // Подтверждение
+	Handshake                                          // Ответ сервера на стартовое сообщение
+	RestartContainer                                   // Перезапуск контейнера
+	PauseContainer                                     // Приостановка контейнера
+	UnpauseContainer                                   // Возобновление контейнера
+	KillContainer                                      // Отправка сигнала контейнеру
+	ContainerLogs                                      // Чтение логов контейнера
+	CancelOperation                                    // Отмена выполняющейся операции
+	ExecContainer                                      // Выполнение команды в контейнере
+	ExecInput                                          // Ввод для интерактивной exec-сессии
+	ExecResize                                         // Изменение размера терминала exec-сессии
+	PullImage                                          // Загрузка образа из реестра
+	CreateContainer                                    // Создание и запуск контейнера по спецификации
+	ComposeUp                                          // Запуск проекта docker compose
+	ComposeDown                                        // Остановка и удаление проекта docker compose
+	ComposeRestart                                     // Перезапуск проекта docker compose
+	RemoveVolume                                       // Удаление тома
+	PruneVolumes                                       // Удаление неиспользуемых томов
+	RemoveNetwork                                      // Удаление сети
+	PruneNetworks                                      // Удаление неиспользуемых сетей
+	DiskUsage                                          // Отчёт об использовании диска
+	PruneContainers                                    // Удаление остановленных контейнеров
+	PruneImages                                        // Удаление неиспользуемых образов
+	PruneBuildCache                                    // Удаление кеша сборки
+	InspectContainerDetails                            // Подробные сведения о контейнере
+	CopyToContainer                                    // Загрузка файла или tar-архива в контейнер
+	CopyFromContainer                                  // Выгрузка файла или каталога из контейнера
+	CopyChunk                                          // Фрагмент данных, загружаемых в контейнер
+	UpdateContainer                                    // Изменение ограничений ресурсов и политики перезапуска контейнера
 )
 
 // Константы для типов исходящих сообщений.
@@ -34,8 +62,48 @@ const (
 	Result                                        // Результат
 	Restarted                                     // Перезапущено
 	None                                          // Нет действия
+	LogChunk                                      // Фрагмент логов контейнера
+	ExecOutput                                    // Фрагмент вывода интерактивной exec-сессии
+	PullProgress                                  // Ход загрузки образа
+	AddedComposeProject                           // Добавлен проект docker compose
+	UpdatedComposeProject                         // Обновлён проект docker compose
+	RemovedComposeProject                         // Удалён проект docker compose
+	AddedDockerVolume                             // Добавлен docker-том
+	RemovedDockerVolume                           // Удалён docker-том
+	AddedDockerNetwork                            // Добавлена docker-сеть
+	UpdatedDockerNetwork                          // Обновлена docker-сеть
+	RemovedDockerNetwork                          // Удалена docker-сеть
+	ContainerUnhealthy                            // Контейнер перешёл в состояние unhealthy
+	ContainerRestarted                            // Контейнер перезапущен средой выполнения
+	CopyData                                      // Фрагмент данных, выгружаемых из контейнера
 )
 
+// ConnectionState определяет состояние соединения с сервером.
+type ConnectionState int32
+
+// Константы для состояний соединения.
+const (
+	Disconnected ConnectionState = iota // Соединение отсутствует
+	Connecting                          // Идёт установка соединения
+	Connected                           // Соединение установлено
+)
+
+// String возвращает текстовое представление состояния соединения.
+//
+// @return название состояния
+func (s ConnectionState) String() string {
+	switch s {
+	case Disconnected:
+		return "disconnected"
+	case Connecting:
+		return "connecting"
+	case Connected:
+		return "connected"
+	default:
+		return "unknown"
+	}
+}
+
 // SentStartMessage представляет сообщение о запуске, отправляемое клиенту.
 //
 // @field Type тип исходящего сообщения
@@ -43,12 +111,77 @@ const (
 // @field Metric метрика системы
 // @field DockerImages список docker-образов
 // @field DockerContainers список docker-контейнеров
+// @field ProtocolVersion версия протокола клиента
+// @field MinProtocolVersion минимальная поддерживаемая версия протокола
+// @field ComposeProjects проекты docker compose, в которые сгруппированы контейнеры
+// @field DockerVolumes список docker-томов с занимаемым местом
+// @field DockerNetworks список docker-сетей
+// @field Agent сведения о сборке клиента
+// @field Capabilities возможности клиента
 type SentStartMessage struct {
-	Type             TypeSentMessage
-	Token            string
-	Metric           *Metric
-	DockerImages     []*DockerImage
-	DockerContainers []*DockerContainer
+	Type               TypeSentMessage
+	Token              string
+	Metric             *Metric
+	DockerImages       []*DockerImage
+	DockerContainers   []*DockerContainer
+	ComposeProjects    []*ComposeProject
+	DockerVolumes      []*DockerVolume
+	DockerNetworks     []*DockerNetwork
+	ProtocolVersion    int
+	MinProtocolVersion int
+	Agent              *AgentInfo
+	Capabilities       *Capabilities
+}
+
+// AgentInfo содержит сведения о сборке клиента и системе.
+//
+// @field Version версия клиента
+// @field Commit ревизия исходного кода
+// @field BuildTime время ревизии исходного кода
+// @field Modified признак сборки из изменённого рабочего дерева
+// @field GoVersion версия Go, которой собран клиент
+// @field Os операционная система
+// @field Arch архитектура процессора
+// @field Hostname имя хоста
+type AgentInfo struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	Modified  bool
+	GoVersion string
+	Os        string
+	Arch      string
+	Hostname  string
+}
+
+// Capabilities описывает возможности клиента.
+//
+// @field Docker доступна ли среда выполнения контейнеров
+// @field Runtime название среды выполнения контейнеров (docker, podman)
+// @field Scripts разрешено ли выполнение скриптов
+// @field Commands разрешено ли выполнение команд
+// @field Restart разрешена ли перезагрузка
+// @field Exec разрешено ли выполнение команд в контейнерах
+// @field Features список поддерживаемых расширений протокола
+type Capabilities struct {
+	Docker   bool
+	Runtime  string
+	Scripts  bool
+	Commands bool
+	Restart  bool
+	Exec     bool
+	Features []string
+}
+
+// HandshakeReply описывает ответ сервера на стартовое сообщение.
+//
+// @field Accepted принят ли клиент сервером
+// @field ProtocolVersion согласованная версия протокола
+// @field Reason причина отказа
+type HandshakeReply struct {
+	Accepted        bool
+	ProtocolVersion int
+	Reason          string
 }
 
 // Metric содержит информацию о метриках системы.
@@ -74,59 +207,607 @@ type Metric struct {
 
 // DockerImage описывает docker-образ.
 //
-// @field Id идентификатор образа
-// @field Name имя образа
+// @field Id идентификатор образа (первые 7 символов хеша как число)
+// @field Name имя образа (первый тег или "<none>:<none>")
+// @field Tags все теги образа
+// @field Digests все дайджесты образа в реестрах
 // @field Size размер образа
 // @field Hash хеш образа
 type DockerImage struct {
-	Id   int
-	Name string
-	Size float64
-	Hash string
+	Id      int
+	Name    string
+	Tags    []string
+	Digests []string
+	Size    float64
+	Hash    string
 }
 
 // DockerContainer описывает docker-контейнер.
 //
-// @field Id идентификатор контейнера
+// @field Id идентификатор контейнера (первые 7 символов хеша как число)
 // @field Name имя контейнера
-// @field ImageId идентификатор образа
+// @field ImageId идентификатор образа (совпадает с DockerImage.Id)
 // @field ImageHash хеш образа
-// @field Status статус контейнера
-// @field Recourses ресурсы контейнера
+// @field Status состояние контейнера (created, running, paused, restarting, exited, dead)
+// @field Recourses ресурсы контейнера (nil, если контейнер не запущен)
+// @field Health состояние проверки HEALTHCHECK (starting, healthy, unhealthy; пустое, если проверки нет)
+// @field HealthOutput вывод последней проверки HEALTHCHECK
+// @field RestartCount число перезапусков контейнера средой выполнения по политике перезапуска
+// @field Labels метки контейнера
+// @field Limits настроенные ограничения ресурсов (нулевые значения — без ограничения)
+// @field RestartPolicy политика перезапуска
 // @field Hash хеш контейнера
+// @field stateUnknown состояние из inspect не получено: Health, HealthOutput, RestartCount, Limits
+// и RestartPolicy не заполнены и не должны сравниваться с предыдущими
 type DockerContainer struct {
-	Id        int
-	Name      string
-	ImageId   int
-	ImageHash string
-	Status    string
-	Recourses string
-	Hash      string
+	Id            int
+	Name          string
+	ImageId       int
+	ImageHash     string
+	Status        string
+	Recourses     *ContainerResources
+	Health        string
+	HealthOutput  string
+	RestartCount  int
+	Labels        map[string]string
+	Limits        *ResourceLimits
+	RestartPolicy *RestartPolicy
+	Hash          string
+	stateUnknown  bool
+}
+
+// DockerVolume описывает docker-том.
+//
+// @field Name имя тома
+// @field Driver драйвер тома
+// @field Mountpoint путь к данным тома на хосте
+// @field Scope область видимости (local или global)
+// @field Labels метки тома
+// @field Size занимаемое место в байтах (-1, если неизвестно; известно в стартовом сообщении и отчёте DiskUsage)
+// @field RefCount число контейнеров, использующих том (-1, если неизвестно; известно в стартовом сообщении и отчёте DiskUsage)
+type DockerVolume struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	Scope      string
+	Labels     map[string]string
+	Size       int64
+	RefCount   int64
+}
+
+// DockerNetwork описывает docker-сеть.
+//
+// @field Id идентификатор сети (первые 7 символов хеша как число)
+// @field Name имя сети
+// @field Driver драйвер сети
+// @field Scope область видимости (local, global или swarm)
+// @field Internal изолирована ли сеть от внешнего мира
+// @field Labels метки сети
+// @field Containers хеши подключённых контейнеров
+// @field Hash хеш сети
+type DockerNetwork struct {
+	Id         int
+	Name       string
+	Driver     string
+	Scope      string
+	Internal   bool
+	Labels     map[string]string
+	Containers []string
+	Hash       string
+}
+
+// ComposeProject описывает проект docker compose, собранный по меткам контейнеров.
+//
+// @field Name имя проекта (метка com.docker.compose.project)
+// @field WorkingDir рабочий каталог проекта на хосте
+// @field ConfigFiles compose-файлы проекта
+// @field Status состояние проекта (running — все контейнеры запущены, partial — часть, stopped — ни одного)
+// @field Services хеши контейнеров по именам сервисов
+type ComposeProject struct {
+	Name        string
+	WorkingDir  string
+	ConfigFiles []string
+	Status      string
+	Services    map[string][]string
+}
+
+// ContainerResources содержит потребление ресурсов контейнером.
+//
+// @field CpuPercent загрузка CPU в процентах (100% — одно ядро)
+// @field MemoryUsage используемая память (в байтах)
+// @field MemoryLimit лимит памяти (в байтах)
+// @field NetworkRx получено по сети (в байтах)
+// @field NetworkTx отправлено по сети (в байтах)
+// @field BlockRead прочитано с дисков (в байтах)
+// @field BlockWrite записано на диски (в байтах)
+// @field Pids количество процессов
+type ContainerResources struct {
+	CpuPercent  float64
+	MemoryUsage uint64
+	MemoryLimit uint64
+	NetworkRx   uint64
+	NetworkTx   uint64
+	BlockRead   uint64
+	BlockWrite  uint64
+	Pids        uint64
 }
 
 // ReceiveMessage представляет входящее сообщение.
 //
 // @field Type тип входящего сообщения
+// @field Id идентификатор запроса, возвращаемый в ответе
 // @field Data данные сообщения
 type ReceiveMessage struct {
 	Type TypeReceivedMessage
+	Id   string
 	Data string
 }
 
 // SentMessage представляет исходящее сообщение.
 //
 // @field Type тип исходящего сообщения
+// @field Id идентификатор запроса, на который отправлен ответ
 // @field Data данные сообщения
 type SentMessage struct {
 	Type TypeSentMessage
+	Id   string
 	Data string
 }
 
+// CommandResult описывает результат выполнения команды, передаваемый в Data ответа.
+//
+// @field Success признак успешного выполнения
+// @field ExitCode код завершения (-1, если процесс не запускался)
+// @field Error текст ошибки
+// @field Output вывод команды
+// @field Stderr вывод ошибок (для команд, разделяющих потоки вывода, например ExecContainer)
+// @field Duration длительность выполнения (в миллисекундах)
+type CommandResult struct {
+	Success  bool
+	ExitCode int
+	Error    string
+	Output   string
+	Stderr   string
+	Duration int64
+}
+
+// ContainerParams содержит параметры команд управления контейнером.
+// Передаётся в Data в виде JSON; для совместимости Data может содержать только хеш контейнера.
+//
+// @field Hash хеш контейнера
+// @field Timeout время ожидания остановки в секундах до принудительного завершения
+// @field Signal сигнал для KillContainer (по умолчанию SIGKILL)
+type ContainerParams struct {
+	Hash    string
+	Timeout *int
+	Signal  string
+}
+
+// LogsParams содержит параметры команды чтения логов контейнера.
+//
+// @field Hash хеш контейнера
+// @field Since начало периода (RFC 3339, Unix-время или длительность, например 10m)
+// @field Until конец периода в том же формате
+// @field Tail число последних строк или "all"
+// @field Follow продолжать передачу новых строк до отмены операции
+// @field Timestamps добавлять время к каждой строке
+type LogsParams struct {
+	Hash       string
+	Since      string
+	Until      string
+	Tail       string
+	Follow     bool
+	Timestamps bool
+}
+
+// OutputChunk содержит фрагмент потокового вывода: логов контейнера или exec-сессии.
+// Передаётся в Data сообщений LogChunk и ExecOutput с Id исходного запроса.
+//
+// @field Stream поток, из которого прочитан фрагмент (stdout или stderr)
+// @field Seq порядковый номер фрагмента в рамках запроса
+// @field Data текст фрагмента (границы фрагментов не разрывают UTF-8 символы)
+type OutputChunk struct {
+	Stream string
+	Seq    int64
+	Data   string
+}
+
+// ExecParams содержит параметры команды выполнения в контейнере.
+//
+// @field Hash хеш контейнера
+// @field Cmd команда и её аргументы
+// @field Env дополнительные переменные окружения в формате KEY=VALUE
+// @field User пользователь, от имени которого выполняется команда
+// @field WorkingDir рабочий каталог
+// @field Tty запустить интерактивную сессию с терминалом: ввод передаётся сообщениями
+// ExecInput и ExecResize, вывод — сообщениями ExecOutput с Id запроса
+type ExecParams struct {
+	Hash       string
+	Cmd        []string
+	Env        []string
+	User       string
+	WorkingDir string
+	Tty        bool
+}
+
+// TerminalSize содержит размер терминала интерактивной exec-сессии.
+//
+// @field Height число строк
+// @field Width число столбцов
+type TerminalSize struct {
+	Height uint
+	Width  uint
+}
+
+// RegistryAuth содержит учётные данные реестра образов.
+//
+// @field Username имя пользователя
+// @field Password пароль или токен доступа
+// @field IdentityToken токен, выданный реестром (вместо имени и пароля)
+// @field ServerAddress адрес реестра
+type RegistryAuth struct {
+	Username      string
+	Password      string
+	IdentityToken string
+	ServerAddress string
+}
+
+// PullParams содержит параметры команды загрузки образа.
+//
+// @field Image ссылка на образ (например, registry.local:5000/app:1.0)
+// @field Platform платформа образа (например, linux/amd64; пустая — платформа хоста)
+// @field Auth учётные данные реестра (nil — анонимный доступ)
+type PullParams struct {
+	Image    string
+	Platform string
+	Auth     *RegistryAuth
+}
+
+// PullStatus описывает ход загрузки одного слоя образа.
+// Передаётся в Data сообщения PullProgress с Id запроса PullImage.
+//
+// @field Layer идентификатор слоя (пустой для сообщений обо всём образе)
+// @field Status состояние загрузки (Pulling fs layer, Downloading, Extracting, Pull complete и т.д.)
+// @field Current загружено байт
+// @field Total размер слоя в байтах (0, если неизвестен)
+type PullStatus struct {
+	Layer   string
+	Status  string
+	Current int64
+	Total   int64
+}
+
+// PortBinding описывает публикацию порта контейнера на хосте.
+//
+// @field ContainerPort порт внутри контейнера
+// @field Protocol протокол (tcp, udp или sctp; по умолчанию tcp)
+// @field HostIp адрес хоста (пустой — все адреса)
+// @field HostPort порт хоста (0 — любой свободный)
+type PortBinding struct {
+	ContainerPort int
+	Protocol      string
+	HostIp        string
+	HostPort      int
+}
+
+// VolumeMount описывает подключение тома или каталога хоста к контейнеру.
+//
+// @field Source абсолютный путь на хосте или имя тома
+// @field Target абсолютный путь внутри контейнера
+// @field ReadOnly подключить только для чтения
+type VolumeMount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// ResourceLimits описывает ограничения ресурсов контейнера. Нулевые значения не ограничивают ресурс.
+//
+// @field CpuShares относительный вес процессора
+// @field CpuPeriod период планировщика CFS в микросекундах
+// @field CpuQuota квота процессорного времени за период в микросекундах
+// @field Memory лимит памяти в байтах
+// @field MemoryReservation мягкий лимит памяти в байтах
+type ResourceLimits struct {
+	CpuShares         int64
+	CpuPeriod         int64
+	CpuQuota          int64
+	Memory            int64
+	MemoryReservation int64
+}
+
+// RestartPolicy описывает политику перезапуска контейнера.
+//
+// @field Name политика (no, always, unless-stopped, on-failure)
+// @field MaxRetries максимальное число перезапусков для on-failure (0 — без ограничения)
+type RestartPolicy struct {
+	Name       string
+	MaxRetries int
+}
+
+// ContainerSpec содержит декларативное описание создаваемого контейнера.
+//
+// @field Image образ контейнера
+// @field Name имя контейнера (пустое — имя выберет среда выполнения)
+// @field Cmd команда и её аргументы (пустая — команда образа)
+// @field Env переменные окружения в формате KEY=VALUE
+// @field Ports публикуемые порты
+// @field Volumes подключаемые тома и каталоги
+// @field RestartPolicy политика перезапуска
+// @field Resources ограничения ресурсов
+// @field Labels метки контейнера
+// @field Network сеть, к которой подключается контейнер (пустая — сеть по умолчанию)
+type ContainerSpec struct {
+	Image         string
+	Name          string
+	Cmd           []string
+	Env           []string
+	Ports         []PortBinding
+	Volumes       []VolumeMount
+	RestartPolicy *RestartPolicy
+	Resources     *ResourceLimits
+	Labels        map[string]string
+	Network       string
+}
+
+// ComposeParams содержит параметры команд управления проектом docker compose.
+//
+// @field File абсолютный путь к compose-файлу на хосте
+// @field Project имя проекта (пустое — определяется compose по каталогу файла)
+// @field Services сервисы, к которым применяется команда (пустой список — все)
+// @field RemoveVolumes удалить тома проекта (только для ComposeDown)
+// @field RemoveOrphans удалить контейнеры сервисов, которых нет в compose-файле (для ComposeUp и ComposeDown)
+type ComposeParams struct {
+	File          string
+	Project       string
+	Services      []string
+	RemoveVolumes bool
+	RemoveOrphans bool
+}
+
+// PruneParams содержит параметры команд удаления неиспользуемых объектов.
+//
+// @field All удалять все неиспользуемые объекты, а не только безымянные (для образов и томов)
+// @field Until удалять только объекты, созданные раньше указанного времени или длительности (например, 24h)
+// @field Labels фильтры по меткам: key или key=value; с префиксом "!" — исключить объекты с такой меткой
+type PruneParams struct {
+	All    bool
+	Until  string
+	Labels []string
+}
+
+// PruneReport описывает результат удаления неиспользуемых объектов. Передаётся в Output результата.
+//
+// @field Deleted идентификаторы удалённых объектов
+// @field SpaceReclaimed освобождённое место в байтах
+type PruneReport struct {
+	Deleted        []string
+	SpaceReclaimed uint64
+}
+
+// DiskUsageCategory описывает использование диска одним видом объектов.
+//
+// @field Total число объектов
+// @field Active число используемых объектов
+// @field Size занимаемое место в байтах
+// @field Reclaimable место в байтах, которое освободится при удалении неиспользуемых объектов
+type DiskUsageCategory struct {
+	Total       int
+	Active      int
+	Size        int64
+	Reclaimable int64
+}
+
+// DiskUsageReport описывает использование диска средой выполнения (как docker system df).
+// Передаётся в Output результата команды DiskUsage.
+//
+// @field Images образы
+// @field Containers контейнеры (записываемые слои)
+// @field Volumes тома
+// @field BuildCache кеш сборки
+// @field VolumeSizes тома с занимаемым местом и числом использующих их контейнеров
+type DiskUsageReport struct {
+	Images      DiskUsageCategory
+	Containers  DiskUsageCategory
+	Volumes     DiskUsageCategory
+	BuildCache  DiskUsageCategory
+	VolumeSizes []*DockerVolume
+}
+
+// ContainerMount описывает подключённый к контейнеру том или каталог хоста.
+//
+// @field Type тип подключения (volume, bind, tmpfs)
+// @field Name имя тома (пустое для bind и tmpfs)
+// @field Source путь на хосте
+// @field Target путь внутри контейнера
+// @field ReadOnly подключено только для чтения
+type ContainerMount struct {
+	Type     string
+	Name     string
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// ContainerEndpoint описывает подключение контейнера к сети.
+//
+// @field Network имя сети
+// @field IpAddress IPv4-адрес контейнера
+// @field Ipv6Address IPv6-адрес контейнера
+// @field Gateway шлюз сети
+// @field MacAddress MAC-адрес интерфейса
+type ContainerEndpoint struct {
+	Network     string
+	IpAddress   string
+	Ipv6Address string
+	Gateway     string
+	MacAddress  string
+}
+
+// ContainerDetails содержит подробные сведения о контейнере.
+// Передаётся в Output результата команды InspectContainerDetails.
+//
+// @field Hash хеш контейнера
+// @field Name имя контейнера
+// @field Image образ, указанный при создании
+// @field ImageHash хеш образа
+// @field Status состояние контейнера
+// @field Health состояние HEALTHCHECK
+// @field Created время создания (RFC 3339)
+// @field StartedAt время последнего запуска (RFC 3339)
+// @field FinishedAt время последней остановки (RFC 3339)
+// @field ExitCode код завершения основного процесса
+// @field OOMKilled был ли процесс завершён из-за нехватки памяти
+// @field Error ошибка последнего запуска
+// @field RestartCount число перезапусков средой выполнения
+// @field Entrypoint точка входа
+// @field Cmd команда и её аргументы
+// @field WorkingDir рабочий каталог
+// @field User пользователь основного процесса
+// @field Env переменные окружения в формате KEY=VALUE; значения секретов скрыты
+// @field Labels метки контейнера
+// @field RestartPolicy политика перезапуска
+// @field Resources ограничения ресурсов
+// @field Ports открытые порты (HostPort 0 — порт не опубликован на хосте)
+// @field Mounts подключённые тома и каталоги
+// @field Networks подключения к сетям
+type ContainerDetails struct {
+	Hash          string
+	Name          string
+	Image         string
+	ImageHash     string
+	Status        string
+	Health        string
+	Created       string
+	StartedAt     string
+	FinishedAt    string
+	ExitCode      int
+	OOMKilled     bool
+	Error         string
+	RestartCount  int
+	Entrypoint    []string
+	Cmd           []string
+	WorkingDir    string
+	User          string
+	Env           []string
+	Labels        map[string]string
+	RestartPolicy RestartPolicy
+	Resources     ResourceLimits
+	Ports         []PortBinding
+	Mounts        []ContainerMount
+	Networks      []ContainerEndpoint
+}
+
+// CopyParams содержит параметры команд копирования файлов в контейнер и из контейнера.
+//
+// @field Hash хеш контейнера
+// @field Path путь внутри контейнера: файл или каталог назначения для CopyToContainer
+// (каталог — при Archive), копируемый файл или каталог для CopyFromContainer
+// @field Archive данные передаются tar-архивом; без Archive передаётся содержимое одного файла
+// @field Mode права создаваемого файла (по умолчанию 0644, только для CopyToContainer без Archive)
+// @field Size размер загружаемых данных в байтах (только для CopyToContainer)
+// @field Sha256 SHA-256 загружаемых данных в hex (только для CopyToContainer)
+type CopyParams struct {
+	Hash    string
+	Path    string
+	Archive bool
+	Mode    int64
+	Size    int64
+	Sha256  string
+}
+
+// FileChunk содержит фрагмент копируемых данных. Передаётся в Data сообщений CopyChunk
+// (загрузка в контейнер) и CopyData (выгрузка из контейнера) с Id исходного запроса.
+//
+// @field Seq порядковый номер фрагмента в рамках запроса
+// @field Data данные фрагмента (base64 в JSON)
+// @field Sha256 SHA-256 данных фрагмента в hex
+// @field Last последний фрагмент загрузки (только для CopyChunk)
+type FileChunk struct {
+	Seq    int64
+	Data   []byte
+	Sha256 string
+	Last   bool
+}
+
+// CopyReport описывает результат копирования. Передаётся в Output результата.
+//
+// @field Path путь внутри контейнера
+// @field Archive данные переданы tar-архивом
+// @field Size размер переданных данных в байтах
+// @field Sha256 SHA-256 переданных данных в hex
+type CopyReport struct {
+	Path    string
+	Archive bool
+	Size    int64
+	Sha256  string
+}
+
+// UpdateParams содержит параметры команды изменения настроек запущенного контейнера.
+//
+// @field Hash хеш контейнера
+// @field Resources новые ограничения ресурсов (нулевые значения оставляют текущие)
+// @field RestartPolicy новая политика перезапуска (nil — без изменений)
+type UpdateParams struct {
+	Hash          string
+	Resources     *ResourceLimits
+	RestartPolicy *RestartPolicy
+}
+
+// UpdateReport описывает настройки контейнера после изменения. Передаётся в Output результата.
+//
+// @field Resources действующие ограничения ресурсов
+// @field RestartPolicy действующая политика перезапуска
+// @field Warnings предупреждения среды выполнения
+type UpdateReport struct {
+	Resources     ResourceLimits
+	RestartPolicy RestartPolicy
+	Warnings      []string
+}
+
 // Config содержит конфигурацию клиента.
 //
 // @field Ip IP-адрес сервера
 // @field Token токен авторизации
+// @field ReconnectMinDelay минимальная задержка перед переподключением (в секундах)
+// @field ReconnectMaxDelay максимальная задержка перед переподключением (в секундах)
+// @field UseTls использовать защищённое соединение (wss)
+// @field CaFile путь к PEM-файлу с доверенными корневыми сертификатами
+// @field CertFile путь к клиентскому сертификату для mTLS
+// @field KeyFile путь к закрытому ключу клиентского сертификата
+// @field PinnedSha256 SHA-256 отпечатки открытого ключа (SPKI) сервера в hex
+// @field MetricInterval интервал отправки метрик (в секундах)
+// @field Runtime среда выполнения контейнеров: docker (по умолчанию) или podman
+// @field RuntimeSocket путь к сокету среды выполнения (по умолчанию определяется автоматически)
+// @field DockerResyncInterval интервал полной сверки docker-контейнеров и образов (в секундах)
+// @field SpoolDir каталог для хранения неотправленных событий
+// @field SpoolMaxSize максимальный размер спула (в мегабайтах)
+// @field PingInterval интервал отправки ping-кадров серверу (в секундах)
+// @field PongTimeout время без ответа сервера, после которого соединение разрывается (в секундах)
+// @field DisableScripts запретить выполнение скриптов
+// @field DisableCommands запретить выполнение команд
+// @field DisableRestart запретить перезагрузку
+// @field DisableExec запретить выполнение команд в контейнерах
 type Config struct {
-	Ip    string
-	Token string
+	Ip                   string
+	Token                string
+	ReconnectMinDelay    int
+	ReconnectMaxDelay    int
+	UseTls               bool
+	CaFile               string
+	CertFile             string
+	KeyFile              string
+	PinnedSha256         []string
+	MetricInterval       int
+	Runtime              string
+	RuntimeSocket        string
+	DockerResyncInterval int
+	SpoolDir             string
+	SpoolMaxSize         int
+	PingInterval         int
+	PongTimeout          int
+	DisableScripts       bool
+	DisableCommands      bool
+	DisableRestart       bool
+	DisableExec          bool
 }
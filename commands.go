@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// commandHandler выполняет входящую команду и возвращает её результат.
+// Контекст отменяется, когда сервер отменяет операцию командой CancelOperation.
+type commandHandler func(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult
+
+// commandHandlers сопоставляет типам входящих сообщений их обработчики.
+var commandHandlers = map[TypeReceivedMessage]commandHandler{
+	StartContainer:          handleStartContainer,
+	StopContainer:           handleStopContainer,
+	RestartContainer:        handleRestartContainer,
+	PauseContainer:          handlePauseContainer,
+	UnpauseContainer:        handleUnpauseContainer,
+	KillContainer:           handleKillContainer,
+	RemoveContainer:         handleRemoveContainer,
+	RemoveImage:             handleRemoveImage,
+	RunScript:               handleRunScript,
+	RunCommand:              handleRunCommand,
+	Restart:                 handleRestart,
+	ContainerLogs:           handleContainerLogs,
+	CancelOperation:         handleCancelOperation,
+	ExecContainer:           handleExecContainer,
+	PullImage:               handlePullImage,
+	CreateContainer:         handleCreateContainer,
+	ComposeUp:               handleComposeUp,
+	ComposeDown:             handleComposeDown,
+	ComposeRestart:          handleComposeRestart,
+	RemoveVolume:            handleRemoveVolume,
+	PruneVolumes:            handlePruneVolumes,
+	RemoveNetwork:           handleRemoveNetwork,
+	PruneNetworks:           handlePruneNetworks,
+	DiskUsage:               handleDiskUsage,
+	PruneContainers:         handlePruneContainers,
+	PruneImages:             handlePruneImages,
+	PruneBuildCache:         handlePruneBuildCache,
+	InspectContainerDetails: handleInspectContainerDetails,
+	CopyToContainer:         handleCopyToContainer,
+	CopyFromContainer:       handleCopyFromContainer,
+	UpdateContainer:         handleUpdateContainer,
+}
+
+// dispatchCommand находит обработчик входящего сообщения и запускает его в отдельной горутине,
+// чтобы долгие команды не блокировали чтение из соединения.
+//
+// @param message указатель на входящее сообщение
+func (c *Communicator) dispatchCommand(session context.Context, message *ReceiveMessage) {
+	if message.Type == Ok {
+		return
+	}
+
+	// Ввод exec-сессий передаётся по порядку прямо из горутины чтения и не требует ответа
+	if message.Type == ExecInput || message.Type == ExecResize {
+		c.terminals.deliver(message)
+		return
+	}
+
+	if message.Type == CopyChunk {
+		c.uploads.deliver(message)
+		return
+	}
+
+	// Сессии регистрируются до запуска обработчика, чтобы не потерять данные, пришедшие раньше него
+	if message.Type == ExecContainer && message.Id != "" {
+		c.terminals.open(message.Id)
+	}
+
+	if message.Type == CopyToContainer && message.Id != "" {
+		c.uploads.open(message.Id)
+	}
+
+	handler, ok := commandHandlers[message.Type]
+
+	if !ok {
+		log.Printf("Получена неизвестная команда: %d", message.Type)
+		c.sendResult(message.Id, Result, &CommandResult{
+			Success:  false,
+			ExitCode: -1,
+			Error:    fmt.Sprintf("неизвестная команда: %d", message.Type),
+		})
+		return
+	}
+
+	// Операция регистрируется до запуска горутины, чтобы CancelOperation,
+	// отправленный сразу после команды, уже мог её найти
+	ctx, done := c.operations.start(session, message.Id)
+
+	go func() {
+		defer done()
+
+		started := time.Now()
+		result := handler(ctx, c, message)
+
+		if !result.Success && ctx.Err() != nil {
+			result = canceledResult()
+		}
+
+		// Результат команды прерванной сессии не отправляется: сервер уже не ждёт этот Id
+		if session.Err() != nil {
+			log.Printf("Команда %s прервана завершением сессии", message.Id)
+			return
+		}
+
+		result.Duration = time.Since(started).Milliseconds()
+
+		replyType := Result
+
+		if message.Type == Restart {
+			replyType = Restarted
+		}
+
+		c.sendResult(message.Id, replyType, result)
+	}()
+}
+
+// sendResult отправляет серверу результат выполнения команды.
+//
+// @param id идентификатор запроса, на который отправляется ответ
+// @param replyType тип исходящего сообщения
+// @param result указатель на результат выполнения команды
+func (c *Communicator) sendResult(id string, replyType TypeSentMessage, result *CommandResult) {
+	var data string
+
+	if c.Protocol() < 2 {
+		// В первой версии протокола результат передаётся строкой
+		data = result.Output
+
+		if !result.Success {
+			data = result.Error
+		}
+	} else {
+		raw, _ := json.Marshal(result)
+		data = string(raw)
+	}
+
+	c.SendMessage(&SentMessage{
+		Type: replyType,
+		Id:   id,
+		Data: data,
+	})
+}
+
+// deniedResult возвращает результат команды, запрещённой конфигурацией клиента.
+//
+// @param what описание запрещённого действия
+// @return указатель на CommandResult
+func deniedResult(what string) *CommandResult {
+	return &CommandResult{
+		Success:  false,
+		ExitCode: -1,
+		Error:    fmt.Sprintf("действие запрещено конфигурацией клиента: %s", what),
+	}
+}
+
+// errorResult преобразует ошибку операции в CommandResult.
+//
+// @param err ошибка операции (nil при успехе)
+// @return указатель на CommandResult
+func errorResult(err error) *CommandResult {
+	if err == nil {
+		return &CommandResult{Success: true}
+	}
+
+	log.Println(err)
+	return &CommandResult{Success: false, ExitCode: -1, Error: err.Error()}
+}
+
+// canceledResult возвращает результат операции, отменённой сервером.
+//
+// @return указатель на CommandResult
+func canceledResult() *CommandResult {
+	return &CommandResult{Success: false, ExitCode: -1, Error: "операция отменена"}
+}
+
+// execResult преобразует результат выполнения скрипта или команды в CommandResult.
+//
+// @param out вывод процесса
+// @param err ошибка выполнения (если есть)
+// @return указатель на CommandResult
+func execResult(out string, err error) *CommandResult {
+	result := &CommandResult{
+		Success:  err == nil,
+		ExitCode: exitCode(err),
+		Output:   out,
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// parseContainerParams разбирает параметры команды управления контейнером.
+// Data может быть JSON-объектом ContainerParams или хешем контейнера.
+//
+// @param data данные команды
+// @return указатель на ContainerParams и ошибка (если есть)
+func parseContainerParams(data string) (*ContainerParams, error) {
+	var params ContainerParams
+	data = strings.TrimSpace(data)
+
+	if strings.HasPrefix(data, "{") {
+		if err := json.Unmarshal([]byte(data), &params); err != nil {
+			return nil, fmt.Errorf("ошибка разбора параметров команды: %v", err)
+		}
+	} else {
+		params.Hash = data
+	}
+
+	if params.Hash == "" {
+		return nil, fmt.Errorf("не указан хеш контейнера")
+	}
+
+	if params.Timeout != nil && *params.Timeout < 0 {
+		return nil, fmt.Errorf("таймаут не может быть отрицательным: %d", *params.Timeout)
+	}
+
+	return &params, nil
+}
+
+// containerCommand создает обработчик команды управления контейнером.
+//
+// @param action операция над контейнером с разобранными параметрами
+// @return commandHandler
+func containerCommand(action func(c *Communicator, ctx context.Context, params *ContainerParams) error) commandHandler {
+	return func(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+		params, err := parseContainerParams(message.Data)
+
+		if err != nil {
+			return errorResult(err)
+		}
+
+		return errorResult(action(c, ctx, params))
+	}
+}
+
+// handleStartContainer обрабатывает команду запуска контейнера.
+var handleStartContainer = containerCommand(func(c *Communicator, ctx context.Context, params *ContainerParams) error {
+	return c.Runtime.StartContainer(ctx, params.Hash)
+})
+
+// handleStopContainer обрабатывает команду остановки контейнера с необязательным таймаутом.
+var handleStopContainer = containerCommand(func(c *Communicator, ctx context.Context, params *ContainerParams) error {
+	return c.Runtime.StopContainer(ctx, params.Hash, params.Timeout)
+})
+
+// handleRestartContainer обрабатывает команду перезапуска контейнера с необязательным таймаутом.
+var handleRestartContainer = containerCommand(func(c *Communicator, ctx context.Context, params *ContainerParams) error {
+	return c.Runtime.RestartContainer(ctx, params.Hash, params.Timeout)
+})
+
+// handlePauseContainer обрабатывает команду приостановки контейнера.
+var handlePauseContainer = containerCommand(func(c *Communicator, ctx context.Context, params *ContainerParams) error {
+	return c.Runtime.PauseContainer(ctx, params.Hash)
+})
+
+// handleUnpauseContainer обрабатывает команду возобновления контейнера.
+var handleUnpauseContainer = containerCommand(func(c *Communicator, ctx context.Context, params *ContainerParams) error {
+	return c.Runtime.UnpauseContainer(ctx, params.Hash)
+})
+
+// handleKillContainer обрабатывает команду отправки сигнала контейнеру.
+var handleKillContainer = containerCommand(func(c *Communicator, ctx context.Context, params *ContainerParams) error {
+	signal := params.Signal
+
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+
+	return c.Runtime.KillContainer(ctx, params.Hash, signal)
+})
+
+// handleRemoveContainer обрабатывает команду удаления контейнера.
+var handleRemoveContainer = containerCommand(func(c *Communicator, ctx context.Context, params *ContainerParams) error {
+	return c.Runtime.RemoveContainer(ctx, params.Hash)
+})
+
+// handleRemoveImage обрабатывает команду удаления образа.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит хеш образа
+// @return указатель на CommandResult
+func handleRemoveImage(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	return errorResult(c.Runtime.RemoveImage(ctx, message.Data))
+}
+
+// handleRunScript обрабатывает команду выполнения скрипта.
+// Отмена операции завершает процесс скрипта.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит текст скрипта
+// @return указатель на CommandResult
+func handleRunScript(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	if c.cfg.DisableScripts {
+		return deniedResult("выполнение скриптов")
+	}
+
+	out, err := runScript(ctx, message.Data)
+
+	if err != nil {
+		log.Printf("Ошибка выполнения скрипта: %v", err)
+	}
+
+	return execResult(out, err)
+}
+
+// handleRunCommand обрабатывает команду выполнения команды ОС.
+// Отмена операции завершает процесс команды.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит строку команды
+// @return указатель на CommandResult
+func handleRunCommand(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	if c.cfg.DisableCommands {
+		return deniedResult("выполнение команд")
+	}
+
+	out, err := runCommand(ctx, message.Data)
+
+	if err != nil {
+		log.Printf("Ошибка выполнения команды: %v", err)
+	}
+
+	return execResult(out, err)
+}
+
+// handleRestart обрабатывает команду перезагрузки компьютера.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message не используется
+// @return указатель на CommandResult
+func handleRestart(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	if c.cfg.DisableRestart {
+		return deniedResult("перезагрузка")
+	}
+
+	err := reboot()
+
+	if err != nil {
+		log.Printf("Ошибка перезагрузки: %v", err)
+		return &CommandResult{Success: false, ExitCode: exitCode(err), Error: err.Error()}
+	}
+
+	return &CommandResult{Success: true, Output: "Ok"}
+}
+
+// handleCancelOperation обрабатывает команду отмены выполняющейся операции.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит идентификатор отменяемого запроса
+// @return указатель на CommandResult
+func handleCancelOperation(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	id := strings.TrimSpace(message.Data)
+
+	if !c.operations.cancel(id) {
+		return errorResult(fmt.Errorf("операция не найдена: %s", id))
+	}
+
+	return &CommandResult{Success: true}
+}
@@ -1,76 +1,458 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// errRejected возвращается, когда сервер отклонил подключение клиента.
+var errRejected = errors.New("подключение отклонено сервером")
+
+// errHeartbeatTimeout возвращается, когда от сервера долго не приходит ни одного кадра.
+var errHeartbeatTimeout = errors.New("пропущен heartbeat")
+
+// errClosed возвращается при отправке сообщения после остановки Communicator.
+var errClosed = errors.New("соединение закрыто")
+
+// snapshotEvents содержит события об изменении состояния, которое целиком передаётся
+// в стартовом сообщении. Такие события, сохранённые в спуле до его формирования, устарели:
+// после перезапуска клиента за ними может не последовать парное событие (например, удаление).
+var snapshotEvents = map[TypeSentMessage]bool{
+	AddedDockerImage:       true,
+	RemovedDockerImage:     true,
+	AddedDockerContainer:   true,
+	RemovedDockerContainer: true,
+	UpdatedDockerContainer: true,
+	AddedComposeProject:    true,
+	UpdatedComposeProject:  true,
+	RemovedComposeProject:  true,
+	AddedDockerVolume:      true,
+	RemovedDockerVolume:    true,
+	AddedDockerNetwork:     true,
+	UpdatedDockerNetwork:   true,
+	RemovedDockerNetwork:   true,
+}
+
+// errSessionEnded возвращается операциям, прерванным завершением сессии с сервером.
+var errSessionEnded = errors.New("сессия с сервером завершена")
+
+// writeTimeout задаёт максимальное время записи одного кадра в соединение.
+const writeTimeout = 10 * time.Second
+
+// outgoingQueueSize задаёт ёмкость очереди сообщений, ожидающих записи в соединение.
+const outgoingQueueSize = 256
+
 // Communicator обеспечивает взаимодействие с сервером по WebSocket.
 //
 // @field Token токен авторизации
 // @field Ip IP-адрес сервера
 // @field Con WebSocket-соединение
-// @field Requests очередь исходящих сообщений
+// @field Requests персистентная очередь событий, ожидающих отправки
+// @field Runtime среда выполнения контейнеров
 type Communicator struct {
 	Token    string
 	Ip       string
 	Con      *websocket.Conn
-	Requests AtomicQueue[*SentMessage]
+	Requests *Spool
+	Runtime  ContainerRuntime
+
+	scheme         string            // Схема URL соединения (ws или wss).
+	dialer         *websocket.Dialer // Диалер для установки соединения.
+	minDelay       time.Duration     // Минимальная задержка перед переподключением.
+	maxDelay       time.Duration     // Максимальная задержка перед переподключением.
+	conMu          sync.RWMutex      // Мьютекс для синхронизации доступа к соединению.
+	state          atomic.Int32      // Текущее состояние соединения (ConnectionState).
+	outgoing       chan *SentMessage // Очередь сообщений для горутины записи.
+	wake           chan struct{}     // Сигнал о новых событиях в Requests.
+	metricInterval time.Duration     // Интервал отправки метрик.
+	pingInterval   time.Duration     // Интервал отправки ping-кадров.
+	pongTimeout    time.Duration     // Время без входящих кадров, после которого соединение считается мёртвым.
+	cfg            *Config           // Конфигурация клиента.
+	protocol       atomic.Int32      // Согласованная с сервером версия протокола.
+	closed         chan struct{}     // Закрывается при остановке Communicator.
+	closeOnce      sync.Once         // Гарантирует однократное закрытие closed.
+	operations     operations        // Выполняющиеся команды, которые можно отменить.
+	terminals      terminals         // Интерактивные exec-сессии.
+	uploads        uploads           // Загрузки данных в контейнеры.
 }
 
 // NewCommunicator создает новый экземпляр Communicator.
 //
-// @param token токен авторизации
-// @param ip IP-адрес сервера
-// @return указатель на Communicator
-func NewCommunicator(token string, ip string) *Communicator {
-	return &Communicator{
-		Token: token,
-		Ip:    ip,
-		Con:   nil,
+// @param cfg конфигурация клиента
+// @param rt среда выполнения контейнеров
+// @return указатель на Communicator и ошибка (если есть)
+func NewCommunicator(cfg *Config, rt ContainerRuntime) (*Communicator, error) {
+	dialer, err := newDialer(cfg)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настройки TLS: %v", err)
+	}
+
+	spool, err := OpenSpool(cfg.SpoolDir, int64(cfg.SpoolMaxSize)*1024*1024)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "ws"
+
+	if cfg.UseTls {
+		scheme = "wss"
+	}
+
+	c := &Communicator{
+		Token:          cfg.Token,
+		Ip:             cfg.Ip,
+		Con:            nil,
+		Requests:       spool,
+		Runtime:        rt,
+		scheme:         scheme,
+		dialer:         dialer,
+		minDelay:       time.Duration(cfg.ReconnectMinDelay) * time.Second,
+		maxDelay:       time.Duration(cfg.ReconnectMaxDelay) * time.Second,
+		outgoing:       make(chan *SentMessage, outgoingQueueSize),
+		wake:           make(chan struct{}, 1),
+		metricInterval: time.Duration(cfg.MetricInterval) * time.Second,
+		pingInterval:   time.Duration(cfg.PingInterval) * time.Second,
+		pongTimeout:    time.Duration(cfg.PongTimeout) * time.Second,
+		cfg:            cfg,
+		closed:         make(chan struct{}),
+	}
+	c.protocol.Store(MinProtocolVersion)
+
+	return c, nil
+}
+
+// Protocol возвращает версию протокола, согласованную с сервером.
+// Пока сервер не подтвердил версию в ответе на стартовое сообщение, используется минимальная
+// версия: серверы, появившиеся до ответа Handshake, не присылают его вовсе.
+//
+// @return версия протокола
+func (c *Communicator) Protocol() int {
+	return int(c.protocol.Load())
+}
+
+// State возвращает текущее состояние соединения с сервером.
+//
+// @return состояние соединения
+func (c *Communicator) State() ConnectionState {
+	return ConnectionState(c.state.Load())
+}
+
+// setState изменяет состояние соединения и логирует переход.
+//
+// @param state новое состояние соединения
+func (c *Communicator) setState(state ConnectionState) {
+	if prev := ConnectionState(c.state.Swap(int32(state))); prev != state {
+		log.Printf("Состояние соединения: %s -> %s", prev, state)
 	}
 }
 
-// SendMessage отправляет сообщение серверу по WebSocket.
+// conn возвращает текущее WebSocket-соединение.
+//
+// @return соединение или nil, если оно не установлено
+func (c *Communicator) conn() *websocket.Conn {
+	c.conMu.RLock()
+	defer c.conMu.RUnlock()
+	return c.Con
+}
+
+// SendMessage ставит сообщение в очередь на отправку серверу.
+// Сообщение будет записано в соединение горутиной записи; если соединения нет,
+// оно будет отправлено после переподключения.
 //
 // @param message указатель на отправляемое сообщение
 func (c *Communicator) SendMessage(message *SentMessage) {
-	err := c.Con.WriteJSON(message)
+	select {
+	case c.outgoing <- message:
+	case <-c.closed:
+		log.Printf("Ошибка отправки сообщения: соединение закрыто")
+	}
+}
 
-	if err != nil {
+// sendContext ставит сообщение в очередь на отправку, блокируясь, пока в очереди нет места.
+// Используется потоковыми командами: медленное соединение притормаживает источник данных.
+//
+// @param ctx контекст операции
+// @param message указатель на отправляемое сообщение
+// @return ошибка, если операция отменена или Communicator остановлен
+func (c *Communicator) sendContext(ctx context.Context, message *SentMessage) error {
+	select {
+	case c.outgoing <- message:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.closed:
+		return errClosed
+	}
+}
+
+// trySend ставит сообщение в очередь на отправку, не блокируясь.
+//
+// @param message указатель на отправляемое сообщение
+// @return false, если очередь отправки переполнена
+func (c *Communicator) trySend(message *SentMessage) bool {
+	select {
+	case c.outgoing <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// Push сохраняет событие в спул Requests и будит горутину записи.
+// В отличие от SendMessage, события из спула не теряются при разрыве соединения
+// или перезапуске клиента и отправляются по порядку после переподключения.
+//
+// @param message указатель на отправляемое сообщение
+func (c *Communicator) Push(message *SentMessage) {
+	if err := c.Requests.Append(message); err != nil {
+		log.Printf("Ошибка сохранения события: %v", err)
+		return
+	}
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// writeLoop последовательно записывает исходящие сообщения в соединение.
+// Во время сессии это единственная горутина, которая пишет в соединение сообщения
+// (ping-кадры отправляет pingLoop): первым отправляется стартовое сообщение, затем
+// накопленные события из Requests, после чего сообщения из очереди отправки и новые события
+// по мере поступления. События об изменении состояния, сохранённые до стартового сообщения,
+// не отправляются: его снимок их заменяет. При ошибке записи соединение закрывается, что прерывает чтение
+// и запускает переподключение.
+//
+// @param conn WebSocket-соединение
+// @param done канал, закрытие которого завершает запись
+func (c *Communicator) writeLoop(conn *websocket.Conn, done <-chan struct{}) {
+	fail := func(err error) {
 		log.Printf("Ошибка отправки сообщения: %v", err)
+		_ = conn.Close()
+	}
+
+	// Позиция запоминается до формирования снимка, чтобы не пропустить события, появившиеся во время него
+	snapshot := c.Requests.End()
+
+	if err := c.write(conn, c.startMessage()); err != nil {
+		fail(err)
+		return
+	}
+
+	if err := c.drainRequests(conn, done, snapshot); err != nil {
+		fail(err)
+		return
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case message := <-c.outgoing:
+			if err := c.write(conn, message); err != nil {
+				fail(err)
+				return
+			}
+		case <-c.wake:
+			if err := c.drainRequests(conn, done, snapshot); err != nil {
+				fail(err)
+				return
+			}
+		}
+	}
+}
+
+// pingLoop периодически отправляет серверу ping-кадры. Работает отдельно от writeLoop,
+// чтобы долгое формирование стартового сообщения или отправка большого спула
+// не приводили к разрыву соединения по таймауту heartbeat на стороне сервера.
+// WriteControl допускает вызов параллельно с записью сообщений.
+//
+// @param conn WebSocket-соединение
+// @param done канал, закрытие которого завершает отправку
+func (c *Communicator) pingLoop(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(c.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				log.Printf("Ошибка отправки ping: %v", err)
+				_ = conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// write записывает сообщение в соединение с ограничением времени записи,
+// чтобы запись в «зависшее» соединение не блокировала горутину навсегда.
+//
+// @param conn WebSocket-соединение
+// @param message отправляемое сообщение
+// @return ошибка записи (если есть)
+func (c *Communicator) write(conn *websocket.Conn, message any) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+
+	return conn.WriteJSON(message)
+}
+
+// drainRequests отправляет все события из спула Requests.
+// Событие подтверждается в спуле только после успешной записи.
+// События из snapshotEvents, сохранённые раньше снимка, подтверждаются без отправки.
+//
+// @param conn WebSocket-соединение
+// @param done канал, закрытие которого прерывает отправку
+// @param snapshot позиция спула на момент формирования стартового сообщения
+// @return ошибка записи (если есть)
+func (c *Communicator) drainRequests(conn *websocket.Conn, done <-chan struct{}, snapshot SpoolPosition) error {
+	for {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+
+		message, err := c.Requests.Peek()
+
+		if err != nil {
+			log.Printf("Ошибка чтения спула: %v", err)
+			return nil
+		}
+
+		if message == nil {
+			return nil
+		}
+
+		stale := snapshotEvents[message.Type] && c.Requests.Position().Before(snapshot)
+
+		if !stale {
+			if err := c.write(conn, message); err != nil {
+				return err
+			}
+		}
+
+		if err := c.Requests.Ack(); err != nil {
+			log.Printf("Ошибка подтверждения события: %v", err)
+		}
+	}
+}
+
+// runSession обслуживает установленное соединение: запускает горутину записи
+// и читает входящие сообщения до разрыва связи. Команды сессии выполняются в её контексте,
+// поэтому после разрыва они отменяются, а интерактивные сессии и загрузки закрываются:
+// после переподключения сервер уже не знает их идентификаторов.
+//
+// @return ошибка, из-за которой сессия была прервана
+func (c *Communicator) runSession() error {
+	conn := c.conn()
+
+	if conn == nil {
+		return fmt.Errorf("соединение не установлено")
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	writerDone := make(chan struct{})
+	pingerDone := make(chan struct{})
+
+	go func() {
+		defer close(writerDone)
+		c.writeLoop(conn, done)
+	}()
+
+	go func() {
+		defer close(pingerDone)
+		c.pingLoop(conn, done)
+	}()
+
+	err := c.handleMessages(ctx, conn)
+
+	cancel()
+	c.terminals.closeAll()
+	c.uploads.closeAll()
+
+	// Дожидаемся завершения записи, чтобы горутины сессии не пережили соединение
+	close(done)
+	<-writerDone
+	<-pingerDone
+
+	return err
 }
 
-// Connect устанавливает WebSocket-соединение с сервером и отправляет стартовое сообщение.
-func (c *Communicator) Connect() {
+// Connect устанавливает WebSocket-соединение с сервером.
+// Стартовое сообщение отправляет горутина записи в начале сессии.
+//
+// @return ошибка, если соединение установить не удалось
+func (c *Communicator) Connect() error {
 	// Формируем URL для соединения по WebSocket
-	u := url.URL{Scheme: "ws", Host: c.Ip, Path: "/ws"}
+	u := url.URL{Scheme: c.scheme, Host: c.Ip, Path: "/ws"}
 	log.Printf("Подключение к серверу: %s", u.String())
+	c.setState(Connecting)
 
 	// Устанавливаем соединение
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	conn, _, err := c.dialer.Dial(u.String(), nil)
 
 	if err != nil {
-		log.Fatalf("Ошибка соединения: %v", err)
+		c.setState(Disconnected)
+		return fmt.Errorf("ошибка соединения: %v", err)
 	}
 
 	// Дальнейшая логика работы с соединением
 	log.Println("Соединение установлено")
+
+	c.conMu.Lock()
 	c.Con = conn
+	c.conMu.Unlock()
+	c.setState(Connected)
 
-	// Отправляем сообщение на сервер
-	c.SendStartMessage()
+	return nil
 }
 
-// Close закрывает WebSocket-соединение.
+// disconnect закрывает текущее соединение и переводит Communicator в состояние Disconnected.
+func (c *Communicator) disconnect() {
+	c.conMu.Lock()
+	conn := c.Con
+	c.Con = nil
+	c.conMu.Unlock()
+
+	if conn != nil {
+		_ = conn.Close()
+	}
+
+	c.setState(Disconnected)
+}
+
+// Close закрывает WebSocket-соединение и останавливает переподключение.
 func (c *Communicator) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+
 	// Закрываем соединение
-	if c.Con != nil {
-		err := c.Con.Close()
+	c.conMu.Lock()
+	conn := c.Con
+	c.Con = nil
+	c.conMu.Unlock()
+
+	if conn != nil {
+		err := conn.Close()
 
 		if err != nil {
 			log.Printf("Ошибка закрытия соединения: %v", err)
@@ -78,140 +460,263 @@ func (c *Communicator) Close() {
 			log.Println("Соединение закрыто")
 		}
 	}
+
+	c.setState(Disconnected)
+	c.Requests.Close()
+}
+
+// wait ожидает истечения задержки или остановки Communicator.
+//
+// @param delay длительность ожидания
+// @return false, если Communicator был остановлен во время ожидания
+func (c *Communicator) wait(delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-c.closed:
+		return false
+	}
 }
 
-// StartHandlingThread запускает горутину для обработки входящих сообщений от сервера.
+// StartHandlingThread запускает горутину, которая поддерживает соединение с сервером:
+// подключается к нему, обрабатывает входящие сообщения и при обрыве связи
+// переподключается с экспоненциальной задержкой. Также запускает периодическую
+// отправку метрик.
 func (c *Communicator) StartHandlingThread() {
-	// Запускаем горутину для обработки сообщений
-	go func() {
-		for {
-			// Читаем сообщения из соединения
-			_, message, err := c.Con.ReadMessage()
+	go c.supervise()
+	go c.streamMetrics()
+}
 
-			if err != nil {
-				log.Printf("Ошибка чтения сообщения: %v", err)
-				break
-			}
+// supervise выполняет цикл подключения и обработки сообщений до остановки Communicator.
+func (c *Communicator) supervise() {
+	backoff := NewBackoff(c.minDelay, c.maxDelay)
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		default:
+		}
 
-			var receiveMessage ReceiveMessage
-			err = json.Unmarshal(message, &receiveMessage)
+		c.protocol.Store(MinProtocolVersion)
 
-			if err != nil {
-				log.Printf("Ошибка декодирования сообщения: %v", err)
-				continue
+		if err := c.Connect(); err != nil {
+			delay := backoff.Next()
+			log.Printf("Ошибка подключения: %v, повторная попытка через %v", err, delay)
+
+			if !c.wait(delay) {
+				return
 			}
 
-			if receiveMessage.Type == StartContainer {
-				_, mess := StartDockerContainer(receiveMessage.Data)
+			continue
+		}
 
-				c.SendMessage(&SentMessage{
-					Type: Result,
-					Data: mess,
-				})
+		connectedAt := time.Now()
+		err := c.runSession()
+		c.disconnect()
 
-				continue
-			}
+		select {
+		case <-c.closed:
+			return
+		default:
+		}
 
-			if receiveMessage.Type == StopContainer {
-				_, mess := StopDockerContainer(receiveMessage.Data)
+		// Сбрасываем задержку, только если соединение продержалось достаточно долго,
+		// чтобы не переподключаться слишком часто к нестабильному серверу
+		if time.Since(connectedAt) >= c.maxDelay {
+			backoff.Reset()
+		}
 
-				c.SendMessage(&SentMessage{
-					Type: Result,
-					Data: mess,
-				})
+		delay := backoff.Next()
 
-				continue
-			}
+		// После отказа сервера нет смысла переподключаться часто
+		if errors.Is(err, errRejected) {
+			delay = c.maxDelay
+		}
 
-			if receiveMessage.Type == RemoveContainer {
-				_, mess := RemoveDockerContainer(receiveMessage.Data)
+		log.Printf("Соединение потеряно: %v, переподключение через %v", err, delay)
 
-				c.SendMessage(&SentMessage{
-					Type: Result,
-					Data: mess,
-				})
+		if !c.wait(delay) {
+			return
+		}
+	}
+}
 
-				continue
-			}
+// handleMessages читает и обрабатывает входящие сообщения от сервера,
+// пока соединение не будет разорвано.
+//
+// @param conn WebSocket-соединение
+// @return ошибка, из-за которой чтение было прервано
+func (c *Communicator) handleMessages(ctx context.Context, conn *websocket.Conn) error {
+	// Любой входящий кадр, включая pong, продлевает срок жизни соединения
+	extend := func() error {
+		return conn.SetReadDeadline(time.Now().Add(c.pongTimeout))
+	}
+
+	if err := extend(); err != nil {
+		return fmt.Errorf("ошибка установки таймаута чтения: %v", err)
+	}
+
+	conn.SetPongHandler(func(string) error {
+		return extend()
+	})
 
-			if receiveMessage.Type == RemoveImage {
-				_, mess := RemoveDockerImage(receiveMessage.Data)
+	for {
+		// Читаем сообщения из соединения
+		_, message, err := conn.ReadMessage()
 
-				c.SendMessage(&SentMessage{
-					Type: Result,
-					Data: mess,
-				})
+		if err != nil {
+			var netErr net.Error
 
-				continue
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				log.Printf("Сервер не отвечает на heartbeat дольше %v, соединение считается разорванным", c.pongTimeout)
+				return fmt.Errorf("%w: нет ответа дольше %v", errHeartbeatTimeout, c.pongTimeout)
 			}
 
-			if receiveMessage.Type == RunScript {
-				out, err := runScript(receiveMessage.Data)
+			return fmt.Errorf("ошибка чтения сообщения: %v", err)
+		}
+
+		if err := extend(); err != nil {
+			return fmt.Errorf("ошибка установки таймаута чтения: %v", err)
+		}
+
+		var receiveMessage ReceiveMessage
+		err = json.Unmarshal(message, &receiveMessage)
 
-				if err != nil {
-					log.Printf("Ошибка выполнения скрипта: %v", err)
-				}
+		if err != nil {
+			log.Printf("Ошибка декодирования сообщения: %v", err)
+			continue
+		}
 
-				c.SendMessage(&SentMessage{Type: Result, Data: out})
-				continue
+		if receiveMessage.Type == Handshake {
+			if err := c.handleHandshake(receiveMessage.Data); err != nil {
+				return err
 			}
 
-			if receiveMessage.Type == RunCommand {
-				out, err := runCommand(receiveMessage.Data)
+			continue
+		}
 
-				if err != nil {
-					log.Printf("Ошибка выполнения команды: %v", err)
-				}
+		c.dispatchCommand(ctx, &receiveMessage)
+	}
+}
 
-				c.SendMessage(&SentMessage{Type: Result, Data: out})
-				continue
-			}
+// handleHandshake обрабатывает ответ сервера на стартовое сообщение:
+// сохраняет согласованную версию протокола или возвращает ошибку при отказе.
+//
+// @param data данные ответа сервера (HandshakeReply в JSON)
+// @return ошибка, оборачивающая errRejected, если сервер отказал клиенту
+func (c *Communicator) handleHandshake(data string) error {
+	var reply HandshakeReply
+
+	if err := json.Unmarshal([]byte(data), &reply); err != nil {
+		log.Printf("Ошибка декодирования ответа сервера: %v", err)
+		return nil
+	}
 
-			if receiveMessage.Type == Restart {
-				err := reboot()
+	if !reply.Accepted {
+		log.Printf("Сервер отклонил подключение: %s", reply.Reason)
+		return fmt.Errorf("%w: %s", errRejected, reply.Reason)
+	}
 
-				if err != nil {
-					log.Printf("Ошибка перезагрузки: %v", err)
-				}
+	version := reply.ProtocolVersion
 
-				c.SendMessage(&SentMessage{
-					Type: Restarted,
-					Data: "Ok",
-				})
+	// Версия повышается только после явного подтверждения сервером
+	if version == 0 {
+		version = MinProtocolVersion
+	}
 
-				continue
-			}
+	if version < MinProtocolVersion || version > ProtocolVersion {
+		return fmt.Errorf("%w: неподдерживаемая версия протокола %d", errRejected, version)
+	}
 
-			if c.Requests.Size() > 0 {
-				message := c.Requests.Pop()
-				c.SendMessage(message)
-				continue
-			}
+	c.protocol.Store(int32(version))
+	log.Printf("Подключение принято сервером, версия протокола: %d", version)
 
-			data, _ := json.Marshal(getMetric())
+	return nil
+}
 
-			c.SendMessage(&SentMessage{
-				Type: SendMetric,
-				Data: string(data),
-			})
+// streamMetrics периодически отправляет метрики системы серверу,
+// не дожидаясь входящих сообщений. Пока соединение не установлено, метрики не отправляются.
+func (c *Communicator) streamMetrics() {
+	ticker := time.NewTicker(c.metricInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
 		}
-	}()
+
+		if c.State() != Connected {
+			continue
+		}
+
+		data, _ := json.Marshal(getMetric())
+
+		if !c.trySend(&SentMessage{Type: SendMetric, Data: string(data)}) {
+			log.Println("Очередь отправки переполнена, метрика пропущена")
+		}
+	}
 }
 
-// SendStartMessage формирует и отправляет стартовое сообщение серверу.
-func (c *Communicator) SendStartMessage() {
-	message := SentStartMessage{
-		Type:             Start,
-		Token:            c.Token,
-		Metric:           getMetric(),
-		DockerImages:     GetAllDockerImages(),
-		DockerContainers: GetAllDockerContainers(),
+// startMessage формирует стартовое сообщение, которое отправляется серверу
+// первым после каждого подключения.
+//
+// @return указатель на SentStartMessage
+func (c *Communicator) startMessage() *SentStartMessage {
+	ctx := context.Background()
+
+	// Без среды выполнения контейнеров клиент продолжает работать: отправляются пустые списки
+	images, err := c.Runtime.Images(ctx)
+
+	if err != nil {
+		log.Printf("Список образов не получен: %v", err)
+		images = []*DockerImage{}
 	}
 
-	// Отправляем сообщение на сервер
-	err := c.Con.WriteJSON(message)
+	containers, err := c.Runtime.Containers(ctx)
 
 	if err != nil {
-		log.Printf("Ошибка отправки сообщения: %v", err)
+		log.Printf("Список контейнеров не получен: %v", err)
+		containers = []*DockerContainer{}
+	}
+
+	// Размеры томов передаются только здесь: в событиях об изменении списка томов их нет
+	volumes, err := c.Runtime.VolumeSizes(ctx)
+
+	if err != nil {
+		log.Printf("Размеры томов не получены: %v", err)
+		volumes, err = c.Runtime.Volumes(ctx)
+	}
+
+	if err != nil {
+		log.Printf("Список томов не получен: %v", err)
+		volumes = []*DockerVolume{}
+	}
+
+	networks, err := c.Runtime.Networks(ctx)
+
+	if err != nil {
+		log.Printf("Список сетей не получен: %v", err)
+		networks = []*DockerNetwork{}
+	}
+
+	return &SentStartMessage{
+		Type:               Start,
+		Token:              c.Token,
+		Metric:             getMetric(),
+		DockerImages:       images,
+		DockerContainers:   containers,
+		ComposeProjects:    composeProjectList(containers),
+		DockerVolumes:      volumes,
+		DockerNetworks:     networks,
+		ProtocolVersion:    ProtocolVersion,
+		MinProtocolVersion: MinProtocolVersion,
+		Agent:              getAgentInfo(),
+		Capabilities:       getCapabilities(c.cfg, c.Runtime, c.Runtime.Available(ctx)),
 	}
 }
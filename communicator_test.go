@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newRecordingServer запускает WebSocket-сервер, передающий типы полученных сообщений в канал.
+func newRecordingServer(t *testing.T) (*httptest.Server, <-chan TypeSentMessage) {
+	t.Helper()
+
+	received := make(chan TypeSentMessage, 64)
+	upgrader := websocket.Upgrader{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		for {
+			var message struct{ Type TypeSentMessage }
+
+			if err := conn.ReadJSON(&message); err != nil {
+				return
+			}
+
+			received <- message.Type
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, received
+}
+
+// nextReceived возвращает тип очередного сообщения, полученного сервером.
+func nextReceived(t *testing.T, received <-chan TypeSentMessage) TypeSentMessage {
+	t.Helper()
+
+	select {
+	case messageType := <-received:
+		return messageType
+	case <-time.After(5 * time.Second):
+		t.Fatal("сервер не получил сообщение")
+		return None
+	}
+}
+
+func TestStartMessageVolumeSizes(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.AddVolume(&DockerVolume{Name: "data", Driver: "local", Size: 4096, RefCount: 1})
+	c := newTestCommunicator(t, rt)
+
+	start := c.startMessage()
+
+	if len(start.DockerVolumes) != 1 || start.DockerVolumes[0].Size != 4096 || start.DockerVolumes[0].RefCount != 1 {
+		t.Fatalf("в стартовом сообщении нет размеров томов: %+v", start.DockerVolumes)
+	}
+}
+
+func TestRestartReplaySkipsStaleInventory(t *testing.T) {
+	dir := t.TempDir()
+
+	// До перезапуска в спуле остались событие о контейнере, удалённом за время простоя, и оповещение
+	before, err := NewCommunicator(&Config{Ip: "127.0.0.1:0", SpoolDir: dir}, NewFakeRuntime())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before.Push(&SentMessage{Type: AddedDockerContainer, Data: "{}"})
+	before.Push(&SentMessage{Type: ContainerUnhealthy, Data: "{}"})
+	before.Close()
+
+	srv, received := newRecordingServer(t)
+	c, err := NewCommunicator(&Config{Ip: strings.TrimPrefix(srv.URL, "http://"), SpoolDir: dir}, NewFakeRuntime())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(c.Close)
+
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	writerDone := make(chan struct{})
+
+	go func() {
+		defer close(writerDone)
+		c.writeLoop(c.conn(), done)
+	}()
+
+	t.Cleanup(func() {
+		close(done)
+		<-writerDone
+	})
+
+	if got := nextReceived(t, received); got != Start {
+		t.Fatalf("первым получено сообщение %d, ожидался Start", got)
+	}
+
+	// Устаревшее событие о составе контейнеров заменено снимком, оповещение сохраняется
+	if got := nextReceived(t, received); got != ContainerUnhealthy {
+		t.Fatalf("после Start получено сообщение %d, ожидалось ContainerUnhealthy", got)
+	}
+
+	// События, сохранённые после снимка, отправляются
+	c.Push(&SentMessage{Type: AddedDockerContainer, Data: "{}"})
+
+	if got := nextReceived(t, received); got != AddedDockerContainer {
+		t.Fatalf("получено сообщение %d, ожидалось AddedDockerContainer", got)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Метки, которыми docker compose помечает контейнеры проекта.
+const (
+	composeProjectLabel     = "com.docker.compose.project"
+	composeServiceLabel     = "com.docker.compose.service"
+	composeWorkingDirLabel  = "com.docker.compose.project.working_dir"
+	composeConfigFilesLabel = "com.docker.compose.project.config_files"
+)
+
+// composeBinaries сопоставляет средам выполнения программу, предоставляющую подкоманду compose.
+var composeBinaries = map[string]string{
+	"docker": "docker",
+	"podman": "podman",
+}
+
+// groupComposeProjects группирует контейнеры в проекты docker compose по их меткам.
+// Контейнеры без метки проекта пропускаются.
+//
+// @param containers список контейнеров
+// @return проекты по имени
+func groupComposeProjects(containers []*DockerContainer) map[string]*ComposeProject {
+	projects := make(map[string]*ComposeProject)
+	running := make(map[string]int)
+	total := make(map[string]int)
+
+	for _, ctr := range containers {
+		name := ctr.Labels[composeProjectLabel]
+
+		if name == "" {
+			continue
+		}
+
+		project, ok := projects[name]
+
+		if !ok {
+			project = &ComposeProject{Name: name, Services: make(map[string][]string)}
+			projects[name] = project
+		}
+
+		if dir := ctr.Labels[composeWorkingDirLabel]; dir != "" {
+			project.WorkingDir = dir
+		}
+
+		if files := ctr.Labels[composeConfigFilesLabel]; files != "" {
+			project.ConfigFiles = strings.Split(files, ",")
+		}
+
+		service := ctr.Labels[composeServiceLabel]
+		project.Services[service] = append(project.Services[service], ctr.Hash)
+
+		total[name]++
+
+		if ctr.Status == "running" {
+			running[name]++
+		}
+	}
+
+	for name, project := range projects {
+		// Сортируем хеши, чтобы сравнение проектов не зависело от порядка контейнеров
+		for _, hashes := range project.Services {
+			slices.Sort(hashes)
+		}
+
+		switch running[name] {
+		case total[name]:
+			project.Status = "running"
+		case 0:
+			project.Status = "stopped"
+		default:
+			project.Status = "partial"
+		}
+	}
+
+	return projects
+}
+
+// composeProjectList возвращает проекты docker compose списком, упорядоченным по имени.
+//
+// @param containers список контейнеров
+// @return срез указателей на ComposeProject
+func composeProjectList(containers []*DockerContainer) []*ComposeProject {
+	projects := groupComposeProjects(containers)
+	list := make([]*ComposeProject, 0, len(projects))
+
+	for _, name := range slices.Sorted(maps.Keys(projects)) {
+		list = append(list, projects[name])
+	}
+
+	return list
+}
+
+// composeProjectChanged сообщает, изменился ли проект docker compose.
+//
+// @param prev предыдущее состояние проекта
+// @param curr текущее состояние проекта
+// @return true, если изменились состояние, файлы или состав сервисов
+func composeProjectChanged(prev *ComposeProject, curr *ComposeProject) bool {
+	return prev.Status != curr.Status ||
+		prev.WorkingDir != curr.WorkingDir ||
+		!slices.Equal(prev.ConfigFiles, curr.ConfigFiles) ||
+		!maps.EqualFunc(prev.Services, curr.Services, slices.Equal)
+}
+
+// handleComposeUp обрабатывает команду запуска проекта docker compose.
+var handleComposeUp = composeCommand(func(params *ComposeParams) []string {
+	args := []string{"up", "--detach"}
+
+	if params.RemoveOrphans {
+		args = append(args, "--remove-orphans")
+	}
+
+	return append(args, params.Services...)
+})
+
+// handleComposeDown обрабатывает команду остановки и удаления проекта docker compose.
+// Список сервисов для down не поддерживается compose и игнорируется.
+var handleComposeDown = composeCommand(func(params *ComposeParams) []string {
+	args := []string{"down"}
+
+	if params.RemoveOrphans {
+		args = append(args, "--remove-orphans")
+	}
+
+	if params.RemoveVolumes {
+		args = append(args, "--volumes")
+	}
+
+	return args
+})
+
+// handleComposeRestart обрабатывает команду перезапуска проекта docker compose.
+var handleComposeRestart = composeCommand(func(params *ComposeParams) []string {
+	return append([]string{"restart"}, params.Services...)
+})
+
+// composeCommand создает обработчик команды docker compose.
+//
+// @param args формирует аргументы подкоманды compose по параметрам команды
+// @return commandHandler
+func composeCommand(args func(params *ComposeParams) []string) commandHandler {
+	return func(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+		var params ComposeParams
+
+		if err := json.Unmarshal([]byte(message.Data), &params); err != nil {
+			return errorResult(fmt.Errorf("ошибка разбора параметров команды: %v", err))
+		}
+
+		if err := validateComposeFile(params.File); err != nil {
+			return errorResult(err)
+		}
+
+		binary, ok := composeBinaries[c.Runtime.Name()]
+
+		if !ok {
+			return errorResult(fmt.Errorf("docker compose не поддерживается средой выполнения %s", c.Runtime.Name()))
+		}
+
+		command := []string{"compose", "--file", params.File}
+
+		if params.Project != "" {
+			command = append(command, "--project-name", params.Project)
+		}
+
+		cmd := exec.CommandContext(ctx, binary, append(command, args(&params)...)...)
+		cmd.Dir = filepath.Dir(params.File)
+		cmd.Env = os.Environ()
+
+		// compose должен работать с тем же демоном, что и клиент
+		if c.cfg.RuntimeSocket != "" && binary == "docker" {
+			cmd.Env = append(cmd.Env, "DOCKER_HOST="+socketHost(c.cfg.RuntimeSocket))
+		}
+
+		out, err := cmd.CombinedOutput()
+		return execResult(string(out), err)
+	}
+}
+
+// validateComposeFile проверяет, что путь указывает на существующий compose-файл.
+//
+// @param file путь к compose-файлу
+// @return ошибка (если есть)
+func validateComposeFile(file string) error {
+	if !filepath.IsAbs(file) {
+		return fmt.Errorf("путь к compose-файлу должен быть абсолютным: %q", file)
+	}
+
+	info, err := os.Stat(file)
+
+	if err != nil {
+		return fmt.Errorf("compose-файл недоступен: %v", err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("compose-файл не является обычным файлом: %q", file)
+	}
+
+	return nil
+}
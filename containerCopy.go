@@ -0,0 +1,336 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"hash"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// copyChunkSize задаёт максимальный размер данных в одном сообщении CopyData.
+const copyChunkSize = 64 * 1024
+
+// copyUploadLimit задаёт максимальный размер данных, загружаемых в контейнер.
+const copyUploadLimit = 1024 * 1024 * 1024
+
+// copyChunkTimeout задаёт время ожидания очередного фрагмента загрузки.
+const copyChunkTimeout = 60 * time.Second
+
+// parseCopyParams разбирает и проверяет параметры команды копирования.
+//
+// @param data данные команды
+// @return указатель на CopyParams и ошибка (если есть)
+func parseCopyParams(data string) (*CopyParams, error) {
+	var params CopyParams
+
+	if err := json.Unmarshal([]byte(data), &params); err != nil {
+		return nil, fmt.Errorf("ошибка разбора параметров команды: %v", err)
+	}
+
+	if params.Hash == "" {
+		return nil, fmt.Errorf("не указан хеш контейнера")
+	}
+
+	if !path.IsAbs(params.Path) {
+		return nil, fmt.Errorf("путь в контейнере должен быть абсолютным: %q", params.Path)
+	}
+
+	return &params, nil
+}
+
+// handleCopyToContainer обрабатывает команду загрузки данных в контейнер.
+// Данные приходят сообщениями CopyChunk с Id запроса и накапливаются во временном файле;
+// в контейнер они копируются только после проверки размера и SHA-256.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит CopyParams в JSON
+// @return указатель на CommandResult
+func handleCopyToContainer(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	if message.Id == "" {
+		return errorResult(fmt.Errorf("загрузка требует идентификатор запроса"))
+	}
+
+	up := c.uploads.get(message.Id)
+	defer c.uploads.close(message.Id)
+
+	if up == nil {
+		return errorResult(errSessionEnded)
+	}
+
+	params, err := parseCopyParams(message.Data)
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	if params.Size < 0 || params.Size > copyUploadLimit {
+		return errorResult(fmt.Errorf("размер данных должен быть от 0 до %d байт: %d", copyUploadLimit, params.Size))
+	}
+
+	if params.Sha256 == "" {
+		return errorResult(fmt.Errorf("не указана контрольная сумма данных"))
+	}
+
+	if params.Mode == 0 {
+		params.Mode = 0644
+	}
+
+	if params.Mode < 0 || params.Mode > 07777 {
+		return errorResult(fmt.Errorf("недопустимые права файла: %o", params.Mode))
+	}
+
+	if !params.Archive && path.Clean(params.Path) == "/" {
+		return errorResult(fmt.Errorf("не указано имя файла в контейнере"))
+	}
+
+	if err := waitUpload(ctx, up); err != nil {
+		return errorResult(err)
+	}
+
+	size, sum, err := up.result()
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	if size != params.Size {
+		return errorResult(fmt.Errorf("получено %d байт из %d", size, params.Size))
+	}
+
+	if !strings.EqualFold(sum, params.Sha256) {
+		return errorResult(fmt.Errorf("контрольная сумма данных не совпадает: %s", sum))
+	}
+
+	if _, err := up.file.Seek(0, io.SeekStart); err != nil {
+		return errorResult(fmt.Errorf("ошибка чтения временного файла: %v", err))
+	}
+
+	dir := params.Path
+	var archive io.Reader = up.file
+
+	if !params.Archive {
+		dir = path.Dir(path.Clean(params.Path))
+		reader := fileArchive(up.file, path.Base(params.Path), size, params.Mode)
+		defer reader.Close()
+		archive = reader
+	}
+
+	if err := c.Runtime.CopyToContainer(ctx, params.Hash, dir, archive); err != nil {
+		return errorResult(err)
+	}
+
+	data, _ := json.Marshal(&CopyReport{Path: params.Path, Archive: params.Archive, Size: size, Sha256: sum})
+	return &CommandResult{Success: true, Output: string(data)}
+}
+
+// waitUpload ждёт последний фрагмент загрузки.
+//
+// @param ctx контекст операции
+// @param up указатель на upload
+// @return ошибка, если операция отменена или фрагменты перестали приходить
+func waitUpload(ctx context.Context, up *upload) error {
+	timer := time.NewTimer(copyChunkTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-up.done:
+			return nil
+		case <-up.progress:
+			timer.Reset(copyChunkTimeout)
+		case <-timer.C:
+			return fmt.Errorf("истекло время ожидания данных загрузки")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// fileArchive упаковывает содержимое одного файла в tar-архив.
+// Архив формируется на лету, поэтому читатель нужно закрыть, даже если он прочитан не полностью.
+//
+// @param file источник содержимого файла
+// @param name имя файла в архиве
+// @param size размер файла
+// @param mode права файла
+// @return io.ReadCloser с tar-архивом
+func fileArchive(file io.Reader, name string, size int64, mode int64) io.ReadCloser {
+	reader, writer := io.Pipe()
+
+	go func() {
+		tw := tar.NewWriter(writer)
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Size:     size,
+			Mode:     mode,
+			ModTime:  time.Now(),
+		})
+
+		if err == nil {
+			_, err = io.Copy(tw, file)
+		}
+
+		if err == nil {
+			err = tw.Close()
+		}
+
+		writer.CloseWithError(err)
+	}()
+
+	return reader
+}
+
+// handleCopyFromContainer обрабатывает команду выгрузки данных из контейнера.
+// Данные передаются сообщениями CopyData, после чего отправляется Result с размером и SHA-256.
+// Без Archive выгружается содержимое одного обычного файла, с Archive — tar-архив пути.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит CopyParams в JSON
+// @return указатель на CommandResult
+func handleCopyFromContainer(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	params, err := parseCopyParams(message.Data)
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	reader, err := c.Runtime.CopyFromContainer(ctx, params.Hash, params.Path)
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	defer reader.Close()
+
+	var src io.Reader = reader
+
+	if !params.Archive {
+		tr := tar.NewReader(reader)
+		header, err := tr.Next()
+
+		if err != nil {
+			return errorResult(fmt.Errorf("ошибка чтения архива: %v", err))
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			return errorResult(fmt.Errorf("%s не является обычным файлом, используйте Archive", params.Path))
+		}
+
+		src = tr
+	}
+
+	stream := &chunkWriter{ctx: ctx, c: c, id: message.Id, sum: sha256.New()}
+
+	if _, err := io.Copy(stream, src); err != nil {
+		if ctx.Err() != nil {
+			return errorResult(ctx.Err())
+		}
+
+		return errorResult(fmt.Errorf("ошибка выгрузки данных: %v", err))
+	}
+
+	data, _ := json.Marshal(&CopyReport{
+		Path:    params.Path,
+		Archive: params.Archive,
+		Size:    stream.size,
+		Sha256:  hex.EncodeToString(stream.sum.Sum(nil)),
+	})
+
+	return &CommandResult{Success: true, Output: string(data)}
+}
+
+// chunkWriter отправляет данные серверу сообщениями CopyData с FileChunk
+// и считает размер и SHA-256 отправленных данных.
+type chunkWriter struct {
+	ctx  context.Context // Контекст операции.
+	c    *Communicator   // Communicator для отправки фрагментов.
+	id   string          // Идентификатор исходного запроса.
+	seq  int64           // Номер следующего фрагмента.
+	size int64           // Число отправленных байт.
+	sum  hash.Hash       // SHA-256 отправленных данных.
+}
+
+// Write отправляет данные серверу фрагментами не длиннее copyChunkSize.
+//
+// @param p данные
+// @return число отправленных байт и ошибка, если операция отменена или соединение закрыто
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+
+	for written < len(p) {
+		end := min(written+copyChunkSize, len(p))
+		part := p[written:end]
+		sum := sha256.Sum256(part)
+
+		data, _ := json.Marshal(&FileChunk{
+			Seq:    w.seq,
+			Data:   part,
+			Sha256: hex.EncodeToString(sum[:]),
+		})
+
+		if err := w.c.sendContext(w.ctx, &SentMessage{Type: CopyData, Id: w.id, Data: string(data)}); err != nil {
+			return written, err
+		}
+
+		w.sum.Write(part)
+		w.size += int64(len(part))
+		w.seq++
+		written = end
+	}
+
+	return written, nil
+}
+
+// CopyToContainer распаковывает tar-архив в каталог контейнера.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @param dir каталог назначения внутри контейнера
+// @param archive tar-архив
+// @return ошибка (если есть)
+func (m *DockerRuntime) CopyToContainer(ctx context.Context, containerHash string, dir string, archive io.Reader) error {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if err := cli.CopyToContainer(ctx, containerHash, dir, archive, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("ошибка копирования в контейнер: %v", err)
+	}
+
+	return nil
+}
+
+// CopyFromContainer возвращает tar-архив файла или каталога контейнера.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @param srcPath путь внутри контейнера
+// @return io.ReadCloser с tar-архивом и ошибка (если есть)
+func (m *DockerRuntime) CopyFromContainer(ctx context.Context, containerHash string, srcPath string) (io.ReadCloser, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reader, _, err := cli.CopyFromContainer(ctx, containerHash, srcPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка копирования из контейнера: %v", err)
+	}
+
+	return reader, nil
+}
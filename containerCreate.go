@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"log"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// minMemoryLimit задаёт минимальный лимит памяти, который принимает Docker.
+const minMemoryLimit = 6 * 1024 * 1024
+
+// containerNamePattern задаёт допустимые имена контейнеров (как в Docker).
+var containerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// restartPolicies содержит допустимые политики перезапуска.
+var restartPolicies = map[string]bool{
+	"no":             true,
+	"always":         true,
+	"unless-stopped": true,
+	"on-failure":     true,
+}
+
+// portProtocols содержит допустимые протоколы публикуемых портов.
+var portProtocols = map[string]bool{
+	"tcp":  true,
+	"udp":  true,
+	"sctp": true,
+}
+
+// handleCreateContainer обрабатывает команду создания и запуска контейнера по спецификации.
+// В Output результата возвращается созданный DockerContainer в JSON.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит ContainerSpec в JSON
+// @return указатель на CommandResult
+func handleCreateContainer(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	spec, err := parseContainerSpec(message.Data)
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	ctr, err := c.Runtime.CreateContainer(ctx, spec)
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	data, _ := json.Marshal(ctr)
+	return &CommandResult{Success: true, Output: string(data)}
+}
+
+// parseContainerSpec разбирает и проверяет спецификацию контейнера.
+// Неизвестные поля считаются ошибкой, чтобы опечатка в спецификации не игнорировалась молча.
+//
+// @param data спецификация в JSON
+// @return указатель на ContainerSpec и ошибка (если есть)
+func parseContainerSpec(data string) (*ContainerSpec, error) {
+	var spec ContainerSpec
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("ошибка разбора спецификации контейнера: %v", err)
+	}
+
+	if err := validateContainerSpec(&spec); err != nil {
+		return nil, fmt.Errorf("некорректная спецификация контейнера: %w", err)
+	}
+
+	return &spec, nil
+}
+
+// validateContainerSpec проверяет спецификацию контейнера и возвращает все найденные ошибки.
+//
+// @param spec указатель на ContainerSpec
+// @return ошибка (если есть)
+func validateContainerSpec(spec *ContainerSpec) error {
+	var errs []error
+
+	if strings.TrimSpace(spec.Image) == "" {
+		errs = append(errs, errors.New("не указан образ"))
+	}
+
+	if spec.Name != "" && !containerNamePattern.MatchString(spec.Name) {
+		errs = append(errs, fmt.Errorf("недопустимое имя контейнера: %q", spec.Name))
+	}
+
+	for _, env := range spec.Env {
+		if key, _, ok := strings.Cut(env, "="); !ok || key == "" {
+			errs = append(errs, fmt.Errorf("переменная окружения должна иметь вид KEY=VALUE: %q", env))
+		}
+	}
+
+	for _, port := range spec.Ports {
+		if port.ContainerPort < 1 || port.ContainerPort > 65535 {
+			errs = append(errs, fmt.Errorf("недопустимый порт контейнера: %d", port.ContainerPort))
+		}
+
+		if port.HostPort < 0 || port.HostPort > 65535 {
+			errs = append(errs, fmt.Errorf("недопустимый порт хоста: %d", port.HostPort))
+		}
+
+		if port.Protocol != "" && !portProtocols[port.Protocol] {
+			errs = append(errs, fmt.Errorf("недопустимый протокол порта: %q", port.Protocol))
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Source == "" {
+			errs = append(errs, fmt.Errorf("не указан источник тома для %q", volume.Target))
+		}
+
+		if !path.IsAbs(volume.Target) {
+			errs = append(errs, fmt.Errorf("путь тома в контейнере должен быть абсолютным: %q", volume.Target))
+		}
+	}
+
+	if spec.RestartPolicy != nil {
+		errs = append(errs, validateRestartPolicy(spec.RestartPolicy)...)
+	}
+
+	if spec.Resources != nil {
+		errs = append(errs, validateResourceLimits(spec.Resources)...)
+	}
+
+	for key := range spec.Labels {
+		if key == "" {
+			errs = append(errs, errors.New("пустое имя метки"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateRestartPolicy проверяет политику перезапуска контейнера.
+//
+// @param policy указатель на RestartPolicy
+// @return срез найденных ошибок
+func validateRestartPolicy(policy *RestartPolicy) []error {
+	var errs []error
+
+	if !restartPolicies[policy.Name] {
+		errs = append(errs, fmt.Errorf("недопустимая политика перезапуска: %q", policy.Name))
+	}
+
+	if policy.MaxRetries < 0 || (policy.MaxRetries > 0 && policy.Name != "on-failure") {
+		errs = append(errs, errors.New("MaxRetries допускается только для политики on-failure и не может быть отрицательным"))
+	}
+
+	return errs
+}
+
+// validateResourceLimits проверяет ограничения ресурсов контейнера.
+//
+// @param limits указатель на ResourceLimits
+// @return срез найденных ошибок
+func validateResourceLimits(limits *ResourceLimits) []error {
+	var errs []error
+
+	if limits.CpuShares < 0 || limits.CpuPeriod < 0 || limits.CpuQuota < 0 || limits.Memory < 0 || limits.MemoryReservation < 0 {
+		errs = append(errs, errors.New("ограничения ресурсов не могут быть отрицательными"))
+	}
+
+	if limits.Memory > 0 && limits.Memory < minMemoryLimit {
+		errs = append(errs, fmt.Errorf("лимит памяти не может быть меньше %d байт", minMemoryLimit))
+	}
+
+	if limits.Memory > 0 && limits.MemoryReservation > limits.Memory {
+		errs = append(errs, errors.New("мягкий лимит памяти не может превышать лимит памяти"))
+	}
+
+	if limits.CpuPeriod != 0 && (limits.CpuPeriod < 1000 || limits.CpuPeriod > 1000000) {
+		errs = append(errs, errors.New("период CPU должен быть от 1000 до 1000000 микросекунд"))
+	}
+
+	if limits.CpuQuota != 0 && limits.CpuQuota < 1000 {
+		errs = append(errs, errors.New("квота CPU не может быть меньше 1000 микросекунд"))
+	}
+
+	return errs
+}
+
+// CreateContainer создает контейнер по спецификации и запускает его.
+// Если контейнер создан, но не запустился, он не удаляется, чтобы причину можно было изучить.
+//
+// @param ctx контекст операции
+// @param spec указатель на проверенную ContainerSpec
+// @return указатель на DockerContainer и ошибка (если есть)
+func (m *DockerRuntime) CreateContainer(ctx context.Context, spec *ContainerSpec) (*DockerContainer, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	config := &container.Config{
+		Image:        spec.Image,
+		Cmd:          spec.Cmd,
+		Env:          spec.Env,
+		Labels:       spec.Labels,
+		ExposedPorts: nat.PortSet{},
+	}
+
+	hostConfig := &container.HostConfig{
+		PortBindings: nat.PortMap{},
+		NetworkMode:  container.NetworkMode(spec.Network),
+	}
+
+	for _, binding := range spec.Ports {
+		protocol := binding.Protocol
+
+		if protocol == "" {
+			protocol = "tcp"
+		}
+
+		port, err := nat.NewPort(protocol, strconv.Itoa(binding.ContainerPort))
+
+		if err != nil {
+			return nil, fmt.Errorf("ошибка публикации порта: %v", err)
+		}
+
+		hostPort := ""
+
+		if binding.HostPort != 0 {
+			hostPort = strconv.Itoa(binding.HostPort)
+		}
+
+		config.ExposedPorts[port] = struct{}{}
+		hostConfig.PortBindings[port] = append(hostConfig.PortBindings[port], nat.PortBinding{HostIP: binding.HostIp, HostPort: hostPort})
+	}
+
+	for _, volume := range spec.Volumes {
+		mountType := mount.TypeVolume
+
+		// Абсолютный путь означает каталог хоста, иначе — именованный том
+		if filepath.IsAbs(volume.Source) {
+			mountType = mount.TypeBind
+		}
+
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:     mountType,
+			Source:   volume.Source,
+			Target:   volume.Target,
+			ReadOnly: volume.ReadOnly,
+		})
+	}
+
+	if spec.RestartPolicy != nil {
+		hostConfig.RestartPolicy = container.RestartPolicy{
+			Name:              container.RestartPolicyMode(spec.RestartPolicy.Name),
+			MaximumRetryCount: spec.RestartPolicy.MaxRetries,
+		}
+	}
+
+	if spec.Resources != nil {
+		hostConfig.Resources = container.Resources{
+			CPUShares:         spec.Resources.CpuShares,
+			CPUPeriod:         spec.Resources.CpuPeriod,
+			CPUQuota:          spec.Resources.CpuQuota,
+			Memory:            spec.Resources.Memory,
+			MemoryReservation: spec.Resources.MemoryReservation,
+		}
+	}
+
+	created, err := cli.ContainerCreate(ctx, config, hostConfig, nil, nil, spec.Name)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания контейнера: %v", err)
+	}
+
+	for _, warning := range created.Warnings {
+		log.Printf("Предупреждение при создании контейнера %s: %s", created.ID, warning)
+	}
+
+	if err := cli.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return nil, fmt.Errorf("контейнер %s создан, но не запущен: %v", created.ID, err)
+	}
+
+	ctr, err := m.InspectContainer(ctx, created.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ctr == nil {
+		return nil, fmt.Errorf("контейнер %s удалён сразу после запуска", created.ID)
+	}
+
+	return ctr, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"io"
+)
+
+// execOutputLimit задаёт максимальный объём каждого потока вывода,
+// возвращаемого в результате неинтерактивного ExecContainer.
+const execOutputLimit = 1024 * 1024
+
+// ExecStreams содержит потоки ввода-вывода exec-сессии.
+//
+// @field Stdin источник ввода (nil — ввод не подключается)
+// @field Stdout получатель стандартного вывода
+// @field Stderr получатель вывода ошибок (при Tty не используется)
+// @field Resize изменения размера терминала (nil — размер не меняется)
+type ExecStreams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Resize <-chan TerminalSize
+}
+
+// cappedBuffer накапливает вывод до заданного предела и отбрасывает остальное,
+// чтобы команда с большим выводом не исчерпала память клиента.
+type cappedBuffer struct {
+	buf       bytes.Buffer // Накопленный вывод.
+	limit     int          // Максимальный размер вывода.
+	truncated bool         // Был ли вывод обрезан.
+}
+
+// Write сохраняет данные в пределах лимита. Всегда сообщает о записи всех байт,
+// чтобы источник вывода не прерывался из-за переполнения буфера.
+//
+// @param p данные вывода
+// @return len(p) и nil
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if free := b.limit - b.buf.Len(); free < len(p) {
+		b.truncated = true
+		b.buf.Write(p[:max(free, 0)])
+	} else {
+		b.buf.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// String возвращает накопленный вывод с пометкой, если он был обрезан.
+//
+// @return накопленный вывод
+func (b *cappedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n[вывод обрезан]"
+	}
+
+	return b.buf.String()
+}
+
+// handleExecContainer обрабатывает команду выполнения в контейнере.
+// Без Tty вывод собирается и возвращается в Output и Stderr результата.
+// С Tty запускается интерактивная сессия: ввод приходит сообщениями ExecInput и ExecResize,
+// вывод отправляется сообщениями ExecOutput, а Result — после завершения процесса.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит ExecParams в JSON
+// @return указатель на CommandResult
+func handleExecContainer(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	defer c.terminals.close(message.Id)
+
+	if c.cfg.DisableExec {
+		return deniedResult("выполнение команд в контейнерах")
+	}
+
+	var params ExecParams
+
+	if err := json.Unmarshal([]byte(message.Data), &params); err != nil {
+		return errorResult(fmt.Errorf("ошибка разбора параметров команды: %v", err))
+	}
+
+	if params.Hash == "" {
+		return errorResult(fmt.Errorf("не указан хеш контейнера"))
+	}
+
+	if len(params.Cmd) == 0 {
+		return errorResult(fmt.Errorf("не указана команда"))
+	}
+
+	if params.Tty {
+		if message.Id == "" {
+			return errorResult(fmt.Errorf("интерактивная сессия требует идентификатор запроса"))
+		}
+
+		term := c.terminals.get(message.Id)
+
+		if term == nil {
+			return errorResult(errSessionEnded)
+		}
+
+		stream := newOutputStream(ctx, c, message.Id, ExecOutput)
+		code, err := c.Runtime.Exec(ctx, &params, &ExecStreams{
+			Stdin:  term.reader(ctx),
+			Stdout: stream.writer("stdout"),
+			Stderr: stream.writer("stderr"),
+			Resize: term.resize,
+		})
+		stream.flush()
+
+		return execExitResult(code, err, "", "")
+	}
+
+	stdout := &cappedBuffer{limit: execOutputLimit}
+	stderr := &cappedBuffer{limit: execOutputLimit}
+	code, err := c.Runtime.Exec(ctx, &params, &ExecStreams{Stdout: stdout, Stderr: stderr})
+
+	return execExitResult(code, err, stdout.String(), stderr.String())
+}
+
+// execExitResult преобразует итог exec-сессии в CommandResult.
+//
+// @param code код завершения процесса
+// @param err ошибка запуска или чтения вывода (если есть)
+// @param stdout стандартный вывод
+// @param stderr вывод ошибок
+// @return указатель на CommandResult
+func execExitResult(code int, err error, stdout string, stderr string) *CommandResult {
+	result := &CommandResult{
+		Success:  err == nil && code == 0,
+		ExitCode: code,
+		Output:   stdout,
+		Stderr:   stderr,
+	}
+
+	if err != nil {
+		result.ExitCode = -1
+		result.Error = err.Error()
+	} else if code != 0 {
+		result.Error = fmt.Sprintf("процесс завершился с кодом %d", code)
+	}
+
+	return result
+}
+
+// Exec выполняет команду в запущенном контейнере и дожидается её завершения.
+//
+// @param ctx контекст операции; его отмена закрывает сессию
+// @param params параметры выполнения
+// @param streams потоки ввода-вывода сессии
+// @return код завершения процесса и ошибка (если есть)
+func (m *DockerRuntime) Exec(ctx context.Context, params *ExecParams, streams *ExecStreams) (int, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return -1, err
+	}
+
+	created, err := cli.ContainerExecCreate(ctx, params.Hash, container.ExecOptions{
+		User:         params.User,
+		Tty:          params.Tty,
+		AttachStdin:  streams.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          params.Env,
+		WorkingDir:   params.WorkingDir,
+		Cmd:          params.Cmd,
+	})
+
+	if err != nil {
+		return -1, fmt.Errorf("ошибка создания exec-сессии: %v", err)
+	}
+
+	resp, err := cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{Tty: params.Tty})
+
+	if err != nil {
+		return -1, fmt.Errorf("ошибка подключения к exec-сессии: %v", err)
+	}
+
+	defer resp.Close()
+
+	session, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Закрываем соединение при отмене операции, чтобы прервать чтение вывода
+	stop := context.AfterFunc(ctx, resp.Close)
+	defer stop()
+
+	if streams.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(resp.Conn, streams.Stdin)
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	if streams.Resize != nil {
+		go func() {
+			for {
+				select {
+				case size := <-streams.Resize:
+					_ = cli.ContainerExecResize(session, created.ID, container.ResizeOptions{Height: size.Height, Width: size.Width})
+				case <-session.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	if params.Tty {
+		_, err = io.Copy(streams.Stdout, resp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(streams.Stdout, streams.Stderr, resp.Reader)
+	}
+
+	if ctx.Err() != nil {
+		return -1, ctx.Err()
+	}
+
+	if err != nil {
+		return -1, fmt.Errorf("ошибка чтения вывода exec-сессии: %v", err)
+	}
+
+	info, err := cli.ContainerExecInspect(ctx, created.ID)
+
+	if err != nil {
+		return -1, fmt.Errorf("ошибка получения кода завершения: %v", err)
+	}
+
+	return info.ExitCode, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"github.com/docker/docker/client"
+	"log"
+	"strings"
+	"time"
+)
+
+// healthOutputLimit задаёт максимальную длину вывода проверки HEALTHCHECK, передаваемого серверу.
+const healthOutputLimit = 4096
+
+// getContainerState заполняет состояние HEALTHCHECK, число перезапусков,
+// ограничения ресурсов и политику перезапуска контейнера.
+//
+// @param ctx контекст операции
+// @param cli Docker-клиент
+// @param cont контейнер, состояние которого нужно получить
+// @return ошибка (если есть)
+func getContainerState(ctx context.Context, cli *client.Client, cont *DockerContainer) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	info, err := cli.ContainerInspect(ctx, cont.Hash)
+
+	if err != nil {
+		return err
+	}
+
+	cont.RestartCount = info.RestartCount
+
+	if info.HostConfig != nil {
+		cont.Limits = &ResourceLimits{
+			CpuShares:         info.HostConfig.CPUShares,
+			CpuPeriod:         info.HostConfig.CPUPeriod,
+			CpuQuota:          info.HostConfig.CPUQuota,
+			Memory:            info.HostConfig.Memory,
+			MemoryReservation: info.HostConfig.MemoryReservation,
+		}
+		cont.RestartPolicy = &RestartPolicy{
+			Name:       string(info.HostConfig.RestartPolicy.Name),
+			MaxRetries: info.HostConfig.RestartPolicy.MaximumRetryCount,
+		}
+	}
+
+	if info.State == nil || info.State.Health == nil {
+		return nil
+	}
+
+	health := info.State.Health
+	output := ""
+
+	if len(health.Log) > 0 {
+		output = strings.TrimSpace(health.Log[len(health.Log)-1].Output)
+	}
+
+	if len(output) > healthOutputLimit {
+		output = output[:healthOutputLimit]
+	}
+
+	cont.Health = health.Status
+	cont.HealthOutput = output
+
+	return nil
+}
+
+// collectState параллельно заполняет состояние HEALTHCHECK, число перезапусков,
+// ограничения ресурсов и политику перезапуска контейнеров.
+// Контейнеры, состояние которых получить не удалось, помечаются stateUnknown.
+//
+// @param ctx контекст операции
+// @param cli Docker-клиент
+// @param conts контейнеры, для которых нужно получить состояние
+func collectState(ctx context.Context, cli *client.Client, conts []*DockerContainer) {
+	forEachContainer(conts, func(cont *DockerContainer) {
+		if err := getContainerState(ctx, cli, cont); err != nil {
+			log.Printf("Ошибка получения состояния контейнера %s: %v", cont.Name, err)
+			cont.stateUnknown = true
+		}
+	})
+}
+
+// carryState переносит в контейнер с неполученным состоянием последние известные значения,
+// чтобы ошибка inspect не выглядела как сброс HEALTHCHECK, перезапусков и настроек.
+//
+// @param prev предыдущее состояние контейнера
+// @param curr текущее состояние контейнера
+func carryState(prev *DockerContainer, curr *DockerContainer) {
+	if !curr.stateUnknown {
+		return
+	}
+
+	curr.Health = prev.Health
+	curr.HealthOutput = prev.HealthOutput
+	curr.RestartCount = prev.RestartCount
+	curr.Limits = prev.Limits
+	curr.RestartPolicy = prev.RestartPolicy
+}
+
+// becameUnhealthy сообщает, перешёл ли контейнер в состояние unhealthy.
+//
+// @param prev предыдущее состояние контейнера (nil для нового контейнера)
+// @param curr текущее состояние контейнера
+// @return true при переходе в unhealthy
+func becameUnhealthy(prev *DockerContainer, curr *DockerContainer) bool {
+	return curr.Health == "unhealthy" && (prev == nil || prev.Health != "unhealthy")
+}
+
+// restartedByRuntime сообщает, перезапускала ли среда выполнения контейнер с прошлой проверки.
+//
+// @param prev предыдущее состояние контейнера
+// @param curr текущее состояние контейнера
+// @return true, если число перезапусков выросло
+func restartedByRuntime(prev *DockerContainer, curr *DockerContainer) bool {
+	return prev != nil && curr.RestartCount > prev.RestartCount
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"io"
+)
+
+// handleContainerLogs обрабатывает команду чтения логов контейнера.
+// Логи передаются сообщениями LogChunk, после чего отправляется итоговый Result.
+// Чтение с Follow продолжается до отмены операции командой CancelOperation.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит LogsParams в JSON
+// @return указатель на CommandResult
+func handleContainerLogs(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	var params LogsParams
+
+	if err := json.Unmarshal([]byte(message.Data), &params); err != nil {
+		return errorResult(fmt.Errorf("ошибка разбора параметров команды: %v", err))
+	}
+
+	if params.Hash == "" {
+		return errorResult(fmt.Errorf("не указан хеш контейнера"))
+	}
+
+	stream := newOutputStream(ctx, c, message.Id, LogChunk)
+	err := c.Runtime.Logs(ctx, &params, stream.writer("stdout"), stream.writer("stderr"))
+	stream.flush()
+
+	return errorResult(err)
+}
+
+// Logs читает логи контейнера и записывает их в stdout и stderr.
+// Для контейнеров с TTY потоки не разделяются и весь вывод пишется в stdout.
+//
+// @param ctx контекст операции; его отмена прерывает чтение с Follow
+// @param params параметры чтения логов
+// @param stdout получатель стандартного вывода
+// @param stderr получатель вывода ошибок
+// @return ошибка (если есть)
+func (m *DockerRuntime) Logs(ctx context.Context, params *LogsParams, stdout io.Writer, stderr io.Writer) error {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	info, err := cli.ContainerInspect(ctx, params.Hash)
+
+	if err != nil {
+		return fmt.Errorf("ошибка получения логов контейнера: %v", err)
+	}
+
+	reader, err := cli.ContainerLogs(ctx, params.Hash, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      params.Since,
+		Until:      params.Until,
+		Tail:       params.Tail,
+		Follow:     params.Follow,
+		Timestamps: params.Timestamps,
+	})
+
+	if err != nil {
+		return fmt.Errorf("ошибка получения логов контейнера: %v", err)
+	}
+
+	defer reader.Close()
+
+	if info.Config != nil && info.Config.Tty {
+		_, err = io.Copy(stdout, reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, reader)
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("ошибка чтения логов контейнера: %v", err)
+	}
+
+	return nil
+}
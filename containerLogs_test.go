@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// runLogs выполняет команду ContainerLogs и возвращает её результат и переданный серверу текст логов.
+func runLogs(t *testing.T, c *Communicator, params *LogsParams) (*CommandResult, string) {
+	t.Helper()
+
+	data, _ := json.Marshal(params)
+	result := handleContainerLogs(context.Background(), c, &ReceiveMessage{Id: "logs-1", Data: string(data)})
+
+	var logs strings.Builder
+
+	for {
+		select {
+		case message := <-c.outgoing:
+			var chunk OutputChunk
+
+			if message.Type != LogChunk || message.Id != "logs-1" {
+				t.Fatalf("неожиданное сообщение: %+v", message)
+			}
+
+			if err := json.Unmarshal([]byte(message.Data), &chunk); err != nil {
+				t.Fatal(err)
+			}
+
+			logs.WriteString(chunk.Data)
+		default:
+			return result, logs.String()
+		}
+	}
+}
+
+func TestHandleContainerLogs(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.AddContainer(newTestContainer(runningHash, "running"))
+	rt.SetLogs(runningHash, "запуск\nготово\n")
+	c := newTestCommunicator(t, rt)
+
+	result, logs := runLogs(t, c, &LogsParams{Hash: runningHash[:12]})
+
+	if !result.Success {
+		t.Fatalf("команда завершилась ошибкой: %s", result.Error)
+	}
+
+	if logs != "запуск\nготово\n" {
+		t.Fatalf("получены логи %q", logs)
+	}
+}
+
+func TestHandleContainerLogsRuntimeUnavailable(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.AddContainer(newTestContainer(runningHash, "running"))
+	rt.SetAvailable(false)
+	c := newTestCommunicator(t, rt)
+
+	result, logs := runLogs(t, c, &LogsParams{Hash: runningHash})
+
+	if result.Success || logs != "" {
+		t.Fatalf("при недоступной среде выполнения ожидалась ошибка, получено %+v и логи %q", result, logs)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// errRuntimeUnavailable возвращается, когда среда выполнения контейнеров недоступна.
+var errRuntimeUnavailable = errors.New("среда выполнения контейнеров недоступна")
+
+// Типы объектов в событиях среды выполнения.
+const (
+	EventContainer = "container" // Событие контейнера
+	EventImage     = "image"     // Событие образа
+	EventVolume    = "volume"    // Событие тома
+	EventNetwork   = "network"   // Событие сети
+)
+
+// RuntimeEvent описывает событие среды выполнения контейнеров.
+// Имена действий совпадают с событиями Docker Engine API (create, start, die, destroy, pull, delete и т.д.).
+//
+// @field Type тип объекта (EventContainer, EventImage, EventVolume или EventNetwork)
+// @field Action действие над объектом
+// @field Id хеш объекта (для томов — имя)
+// @field Attributes дополнительные атрибуты события
+type RuntimeEvent struct {
+	Type       string
+	Action     string
+	Id         string
+	Attributes map[string]string
+}
+
+// ContainerRuntime описывает среду выполнения контейнеров, с которой работает клиент.
+type ContainerRuntime interface {
+	// Name возвращает название среды выполнения.
+	Name() string
+
+	// Available проверяет, отвечает ли среда выполнения на запросы.
+	Available(ctx context.Context) bool
+
+	// Images возвращает список всех образов.
+	Images(ctx context.Context) ([]*DockerImage, error)
+
+	// Containers возвращает список всех контейнеров, включая остановленные.
+	Containers(ctx context.Context) ([]*DockerContainer, error)
+
+	// InspectContainer возвращает контейнер по хешу или nil, если он не найден.
+	InspectContainer(ctx context.Context, containerHash string) (*DockerContainer, error)
+
+	// StartContainer запускает контейнер.
+	StartContainer(ctx context.Context, containerHash string) error
+
+	// StopContainer останавливает контейнер, ожидая timeout секунд до принудительного завершения.
+	StopContainer(ctx context.Context, containerHash string, timeout *int) error
+
+	// RestartContainer перезапускает контейнер, ожидая timeout секунд до принудительного завершения.
+	RestartContainer(ctx context.Context, containerHash string, timeout *int) error
+
+	// PauseContainer приостанавливает все процессы контейнера.
+	PauseContainer(ctx context.Context, containerHash string) error
+
+	// UnpauseContainer возобновляет работу приостановленного контейнера.
+	UnpauseContainer(ctx context.Context, containerHash string) error
+
+	// KillContainer отправляет сигнал главному процессу контейнера.
+	KillContainer(ctx context.Context, containerHash string, signal string) error
+
+	// RemoveContainer принудительно удаляет контейнер.
+	RemoveContainer(ctx context.Context, containerHash string) error
+
+	// RemoveImage принудительно удаляет образ.
+	RemoveImage(ctx context.Context, imageHash string) error
+
+	// Logs читает логи контейнера и записывает их в stdout и stderr до конца логов или отмены ctx.
+	Logs(ctx context.Context, params *LogsParams, stdout io.Writer, stderr io.Writer) error
+
+	// Exec выполняет команду в запущенном контейнере и возвращает её код завершения.
+	Exec(ctx context.Context, params *ExecParams, streams *ExecStreams) (int, error)
+
+	// PullImage загружает образ из реестра, сообщая о ходе загрузки, и возвращает его дайджест.
+	PullImage(ctx context.Context, params *PullParams, progress func(*PullStatus) error) (string, error)
+
+	// CreateContainer создает контейнер по проверенной спецификации, запускает его и возвращает его описание.
+	CreateContainer(ctx context.Context, spec *ContainerSpec) (*DockerContainer, error)
+
+	// Volumes возвращает список всех томов без размеров (Size и RefCount равны -1).
+	Volumes(ctx context.Context) ([]*DockerVolume, error)
+
+	// VolumeSizes возвращает список всех томов с занимаемым ими местом. Подсчёт размеров
+	// обходит содержимое томов, поэтому вызывается только для стартового сообщения.
+	VolumeSizes(ctx context.Context) ([]*DockerVolume, error)
+
+	// Networks возвращает список всех сетей с подключёнными контейнерами.
+	Networks(ctx context.Context) ([]*DockerNetwork, error)
+
+	// RemoveVolume удаляет том.
+	RemoveVolume(ctx context.Context, name string) error
+
+	// RemoveNetwork удаляет сеть.
+	RemoveNetwork(ctx context.Context, networkHash string) error
+
+	// PruneVolumes удаляет неиспользуемые тома.
+	PruneVolumes(ctx context.Context, params *PruneParams) (*PruneReport, error)
+
+	// PruneNetworks удаляет неиспользуемые сети.
+	PruneNetworks(ctx context.Context, params *PruneParams) (*PruneReport, error)
+
+	// DiskUsage возвращает отчёт об использовании диска.
+	DiskUsage(ctx context.Context) (*DiskUsageReport, error)
+
+	// PruneContainers удаляет остановленные контейнеры.
+	PruneContainers(ctx context.Context, params *PruneParams) (*PruneReport, error)
+
+	// PruneImages удаляет неиспользуемые образы.
+	PruneImages(ctx context.Context, params *PruneParams) (*PruneReport, error)
+
+	// PruneBuildCache удаляет кеш сборки.
+	PruneBuildCache(ctx context.Context, params *PruneParams) (*PruneReport, error)
+
+	// InspectDetails возвращает подробные сведения о контейнере.
+	InspectDetails(ctx context.Context, containerHash string) (*ContainerDetails, error)
+
+	// CopyToContainer распаковывает tar-архив в каталог контейнера.
+	CopyToContainer(ctx context.Context, containerHash string, dir string, archive io.Reader) error
+
+	// CopyFromContainer возвращает tar-архив файла или каталога контейнера.
+	CopyFromContainer(ctx context.Context, containerHash string, srcPath string) (io.ReadCloser, error)
+
+	// UpdateContainer изменяет ограничения ресурсов и политику перезапуска контейнера.
+	UpdateContainer(ctx context.Context, params *UpdateParams) (*UpdateReport, error)
+
+	// Events подписывается на события контейнеров, образов, томов и сетей до отмены ctx или ошибки.
+	Events(ctx context.Context) (<-chan RuntimeEvent, <-chan error)
+}
+
+// Проверяем, что реализация удовлетворяет интерфейсу ContainerRuntime.
+var _ ContainerRuntime = (*DockerRuntime)(nil)
+
+// NewContainerRuntime создает среду выполнения контейнеров, указанную в конфигурации.
+//
+// @param cfg конфигурация клиента
+// @return ContainerRuntime и ошибка (если есть)
+func NewContainerRuntime(cfg *Config) (ContainerRuntime, error) {
+	switch strings.ToLower(cfg.Runtime) {
+	case "", "docker":
+		return NewDockerRuntime(cfg.RuntimeSocket), nil
+	case "podman":
+		return NewPodmanRuntime(cfg.RuntimeSocket), nil
+	default:
+		return nil, fmt.Errorf("неизвестная среда выполнения контейнеров: %s", cfg.Runtime)
+	}
+}
+
+// socketHost преобразует путь к сокету в адрес для Docker-клиента.
+// Адреса со схемой (unix://, tcp://, npipe://) возвращаются без изменений.
+//
+// @param socket путь к сокету или адрес
+// @return адрес для client.WithHost
+func socketHost(socket string) string {
+	if strings.Contains(socket, "://") {
+		return socket
+	}
+
+	return "unix://" + socket
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// statsTimeout задаёт максимальное время получения статистики одного контейнера.
+const statsTimeout = 10 * time.Second
+
+// inspectConcurrency ограничивает число одновременных запросов статистики и состояния контейнеров,
+// чтобы хост с сотнями контейнеров не получал сотни запросов к демону разом.
+const inspectConcurrency = 8
+
+// Пороги, при превышении которых изменение ресурсов контейнера считается значимым.
+const (
+	cpuChangeThreshold    = 5.0  // Изменение загрузки CPU в процентных пунктах
+	memoryChangeThreshold = 0.05 // Изменение памяти в долях от лимита
+)
+
+// getContainerResources получает текущее потребление ресурсов контейнера.
+//
+// @param ctx контекст операции
+// @param cli Docker-клиент
+// @param containerHash хеш контейнера
+// @return указатель на ContainerResources и ошибка (если есть)
+func getContainerResources(ctx context.Context, cli *client.Client, containerHash string) (*ContainerResources, error) {
+	ctx, cancel := context.WithTimeout(ctx, statsTimeout)
+	defer cancel()
+
+	// Без потока демон делает два замера, поэтому заполняется PreCPUStats для расчёта загрузки CPU
+	reader, err := cli.ContainerStats(ctx, containerHash, false)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики контейнера: %v", err)
+	}
+
+	defer func() {
+		_ = reader.Body.Close()
+	}()
+
+	var stats container.StatsResponse
+
+	if err := json.NewDecoder(reader.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("ошибка декодирования статистики контейнера: %v", err)
+	}
+
+	res := &ContainerResources{
+		CpuPercent:  cpuPercent(&stats),
+		MemoryUsage: memoryUsage(&stats.MemoryStats),
+		MemoryLimit: stats.MemoryStats.Limit,
+		Pids:        stats.PidsStats.Current,
+	}
+
+	for _, network := range stats.Networks {
+		res.NetworkRx += network.RxBytes
+		res.NetworkTx += network.TxBytes
+	}
+
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			res.BlockRead += entry.Value
+		case "write":
+			res.BlockWrite += entry.Value
+		}
+	}
+
+	return res, nil
+}
+
+// cpuPercent вычисляет загрузку CPU контейнером в процентах (100% — одно ядро).
+//
+// @param stats статистика контейнера
+// @return загрузка CPU в процентах
+func cpuPercent(stats *container.StatsResponse) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	cpus := float64(stats.CPUStats.OnlineCPUs)
+
+	if cpus == 0 {
+		cpus = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	return math.Round(cpuDelta/systemDelta*cpus*10000) / 100
+}
+
+// memoryUsage вычисляет используемую контейнером память без учёта неактивного файлового кеша.
+//
+// @param stats статистика памяти контейнера
+// @return используемая память в байтах
+func memoryUsage(stats *container.MemoryStats) uint64 {
+	// cgroup v1 — total_inactive_file, cgroup v2 — inactive_file
+	for _, key := range []string{"total_inactive_file", "inactive_file"} {
+		if inactive, ok := stats.Stats[key]; ok && inactive < stats.Usage {
+			return stats.Usage - inactive
+		}
+	}
+
+	return stats.Usage
+}
+
+// forEachContainer параллельно вызывает fn для каждого контейнера,
+// выполняя не более inspectConcurrency вызовов одновременно, и ждёт их завершения.
+//
+// @param conts срез контейнеров
+// @param fn функция, вызываемая для контейнера
+func forEachContainer(conts []*DockerContainer, fn func(*DockerContainer)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, inspectConcurrency)
+
+	for _, cont := range conts {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(cont *DockerContainer) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			fn(cont)
+		}(cont)
+	}
+
+	wg.Wait()
+}
+
+// collectResources параллельно заполняет ресурсы для запущенных контейнеров.
+//
+// @param ctx контекст операции
+// @param cli Docker-клиент
+// @param conts срез контейнеров
+func collectResources(ctx context.Context, cli *client.Client, conts []*DockerContainer) {
+	var running []*DockerContainer
+
+	for _, cont := range conts {
+		if cont.Status == "running" {
+			running = append(running, cont)
+		}
+	}
+
+	forEachContainer(running, func(cont *DockerContainer) {
+		res, err := getContainerResources(ctx, cli, cont.Hash)
+
+		if err != nil {
+			log.Printf("Ошибка получения ресурсов контейнера %s: %v", cont.Name, err)
+			return
+		}
+
+		cont.Recourses = res
+	})
+}
+
+// resourcesChanged сообщает, изменилось ли потребление ресурсов настолько,
+// что об этом стоит сообщить серверу. Накопительные счётчики сети и дисков
+// не учитываются, так как растут постоянно.
+//
+// @param prev предыдущие ресурсы контейнера
+// @param curr текущие ресурсы контейнера
+// @return true, если изменение значимо
+func resourcesChanged(prev *ContainerResources, curr *ContainerResources) bool {
+	if prev == nil || curr == nil {
+		return prev != curr
+	}
+
+	if math.Abs(curr.CpuPercent-prev.CpuPercent) >= cpuChangeThreshold {
+		return true
+	}
+
+	if prev.MemoryLimit != curr.MemoryLimit || prev.Pids != curr.Pids {
+		return true
+	}
+
+	base := float64(curr.MemoryLimit)
+
+	if base == 0 {
+		base = float64(prev.MemoryUsage)
+	}
+
+	return math.Abs(float64(curr.MemoryUsage)-float64(prev.MemoryUsage)) >= base*memoryChangeThreshold
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+)
+
+// handleUpdateContainer обрабатывает команду изменения ограничений ресурсов и политики перезапуска
+// запущенного контейнера. В Output результата возвращается UpdateReport с действующими настройками;
+// изменение также приходит серверу событием UpdatedDockerContainer.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит UpdateParams в JSON
+// @return указатель на CommandResult
+func handleUpdateContainer(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	params, err := parseUpdateParams(message.Data)
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	report, err := c.Runtime.UpdateContainer(ctx, params)
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	data, _ := json.Marshal(report)
+	return &CommandResult{Success: true, Output: string(data)}
+}
+
+// parseUpdateParams разбирает и проверяет параметры команды изменения настроек контейнера.
+//
+// @param data данные команды
+// @return указатель на UpdateParams и ошибка (если есть)
+func parseUpdateParams(data string) (*UpdateParams, error) {
+	var params UpdateParams
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&params); err != nil {
+		return nil, fmt.Errorf("ошибка разбора параметров команды: %v", err)
+	}
+
+	if params.Hash == "" {
+		return nil, fmt.Errorf("не указан хеш контейнера")
+	}
+
+	if params.Resources == nil && params.RestartPolicy == nil {
+		return nil, fmt.Errorf("не указаны изменяемые настройки")
+	}
+
+	var errs []error
+
+	if params.Resources != nil {
+		errs = append(errs, validateResourceLimits(params.Resources)...)
+	}
+
+	if params.RestartPolicy != nil {
+		errs = append(errs, validateRestartPolicy(params.RestartPolicy)...)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("некорректные настройки контейнера: %w", err)
+	}
+
+	return &params, nil
+}
+
+// UpdateContainer изменяет ограничения ресурсов и политику перезапуска контейнера без его перезапуска.
+//
+// @param ctx контекст операции
+// @param params указатель на проверенные UpdateParams
+// @return указатель на UpdateReport с действующими настройками и ошибка (если есть)
+func (m *DockerRuntime) UpdateContainer(ctx context.Context, params *UpdateParams) (*UpdateReport, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	update := container.UpdateConfig{}
+
+	// Нулевые значения Docker не меняет, поэтому неуказанные настройки сохраняются
+	if params.Resources != nil {
+		update.Resources = container.Resources{
+			CPUShares:         params.Resources.CpuShares,
+			CPUPeriod:         params.Resources.CpuPeriod,
+			CPUQuota:          params.Resources.CpuQuota,
+			Memory:            params.Resources.Memory,
+			MemoryReservation: params.Resources.MemoryReservation,
+		}
+	}
+
+	if params.RestartPolicy != nil {
+		update.RestartPolicy = container.RestartPolicy{
+			Name:              container.RestartPolicyMode(params.RestartPolicy.Name),
+			MaximumRetryCount: params.RestartPolicy.MaxRetries,
+		}
+	}
+
+	resp, err := cli.ContainerUpdate(ctx, params.Hash, update)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка изменения настроек контейнера: %v", err)
+	}
+
+	details, err := m.InspectDetails(ctx, params.Hash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdateReport{
+		Resources:     details.Resources,
+		RestartPolicy: details.RestartPolicy,
+		Warnings:      append([]string{}, resp.Warnings...),
+	}, nil
+}
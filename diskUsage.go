@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/docker/docker/api/types"
+)
+
+// handleDiskUsage обрабатывает команду получения отчёта об использовании диска.
+// Отчёт DiskUsageReport передаётся в Output результата в JSON.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message не используется
+// @return указатель на CommandResult
+func handleDiskUsage(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	report, err := c.Runtime.DiskUsage(ctx)
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	data, _ := json.Marshal(report)
+	return &CommandResult{Success: true, Output: string(data)}
+}
+
+// DiskUsage возвращает отчёт об использовании диска образами, контейнерами, томами и кешем сборки.
+// Освобождаемое место считается так же, как в docker system df.
+//
+// @param ctx контекст операции
+// @return указатель на DiskUsageReport и ошибка (если есть)
+func (m *DockerRuntime) DiskUsage(ctx context.Context) (*DiskUsageReport, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := cli.DiskUsage(ctx, types.DiskUsageOptions{})
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения использования диска: %v", err)
+	}
+
+	report := &DiskUsageReport{VolumeSizes: make([]*DockerVolume, 0, len(usage.Volumes))}
+
+	// Общие слои образов учитываются один раз, поэтому размер образов — это размер слоёв
+	report.Images.Size = usage.LayersSize
+	var used int64
+
+	for _, img := range usage.Images {
+		report.Images.Total++
+
+		if img.Containers > 0 {
+			report.Images.Active++
+
+			if img.Size != -1 && img.SharedSize != -1 {
+				used += img.Size - img.SharedSize
+			}
+		}
+	}
+
+	report.Images.Reclaimable = max(usage.LayersSize-used, 0)
+
+	for _, cont := range usage.Containers {
+		report.Containers.Total++
+		report.Containers.Size += cont.SizeRw
+
+		if cont.State == "running" {
+			report.Containers.Active++
+		} else {
+			report.Containers.Reclaimable += cont.SizeRw
+		}
+	}
+
+	for _, vol := range usage.Volumes {
+		report.Volumes.Total++
+		report.VolumeSizes = append(report.VolumeSizes, toDockerVolume(vol))
+
+		if vol.UsageData == nil || vol.UsageData.Size < 0 {
+			continue
+		}
+
+		report.Volumes.Size += vol.UsageData.Size
+
+		if vol.UsageData.RefCount > 0 {
+			report.Volumes.Active++
+		} else {
+			report.Volumes.Reclaimable += vol.UsageData.Size
+		}
+	}
+
+	for _, cache := range usage.BuildCache {
+		report.BuildCache.Total++
+
+		if cache.InUse {
+			report.BuildCache.Active++
+		}
+
+		if !cache.Shared {
+			report.BuildCache.Size += cache.Size
+
+			if !cache.InUse {
+				report.BuildCache.Reclaimable += cache.Size
+			}
+		}
+	}
+
+	return report, nil
+}
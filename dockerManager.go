@@ -4,159 +4,485 @@ import (
 	"context"
 	"fmt"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
 	"log"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-// GetAllDockerImages возвращает список всех docker-образов на хосте.
+// dockerHealthInterval задаёт, как часто проверяется доступность docker-демона.
+const dockerHealthInterval = 5 * time.Second
+
+// dockerPingTimeout задаёт максимальное время проверки доступности docker-демона.
+const dockerPingTimeout = 3 * time.Second
+
+// DockerRuntime реализует ContainerRuntime через Docker Engine API и управляет
+// одним долгоживущим клиентом. Доступность демона периодически проверяется;
+// если демон недоступен, операции возвращают ошибку, а клиент пересоздаётся,
+// когда демон снова начинает отвечать. Через совместимый API работает и Podman.
+type DockerRuntime struct {
+	name      string         // Название среды выполнения.
+	mu        sync.Mutex     // Мьютекс для синхронизации доступа к клиенту.
+	opts      []client.Opt   // Параметры создания клиента.
+	cli       *client.Client // Текущий Docker-клиент.
+	available bool           // Отвечал ли демон при последней проверке.
+	checkedAt time.Time      // Время последней проверки доступности.
+}
+
+// NewDockerRuntime создает среду выполнения Docker.
+// Клиент создаётся лениво при первом обращении.
+//
+// @param socket путь к сокету демона (пустая строка — из переменных окружения DOCKER_*)
+// @return указатель на DockerRuntime
+func NewDockerRuntime(socket string) *DockerRuntime {
+	opts := []client.Opt{client.FromEnv}
+
+	if socket != "" {
+		opts = append(opts, client.WithHost(socketHost(socket)))
+	}
+
+	return newApiRuntime("docker", opts...)
+}
+
+// newApiRuntime создает среду выполнения, работающую через Docker Engine API.
+//
+// @param name название среды выполнения
+// @param opts параметры создания Docker-клиента
+// @return указатель на DockerRuntime
+func newApiRuntime(name string, opts ...client.Opt) *DockerRuntime {
+	return &DockerRuntime{
+		name: name,
+		opts: append(opts, client.WithAPIVersionNegotiation()),
+	}
+}
+
+// Name возвращает название среды выполнения.
+//
+// @return название среды выполнения
+func (m *DockerRuntime) Name() string {
+	return m.name
+}
+
+// client возвращает Docker-клиент, если демон доступен.
+//
+// @param ctx контекст операции
+// @return указатель на client.Client и ошибка, оборачивающая errRuntimeUnavailable
+func (m *DockerRuntime) client(ctx context.Context) (*client.Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cli != nil && m.available && time.Since(m.checkedAt) < dockerHealthInterval {
+		return m.cli, nil
+	}
+
+	if m.cli == nil {
+		cli, err := client.NewClientWithOpts(m.opts...)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: ошибка создания клиента %s: %v", errRuntimeUnavailable, m.name, err)
+		}
+
+		m.cli = cli
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, dockerPingTimeout)
+	defer cancel()
+
+	_, err := m.cli.Ping(pingCtx)
+	m.checkedAt = time.Now()
+
+	if err != nil {
+		if m.available {
+			log.Printf("Среда выполнения %s перестала отвечать: %v", m.name, err)
+		}
+
+		// Пересоздаём клиент при следующем обращении, чтобы заново согласовать версию API
+		_ = m.cli.Close()
+		m.cli = nil
+		m.available = false
+
+		return nil, fmt.Errorf("%w: %s: %v", errRuntimeUnavailable, m.name, err)
+	}
+
+	if !m.available {
+		log.Printf("Среда выполнения %s доступна", m.name)
+	}
+
+	m.available = true
+	return m.cli, nil
+}
+
+// Available проверяет, доступен ли демон среды выполнения.
+//
+// @param ctx контекст операции
+// @return true, если демон отвечает на запросы
+func (m *DockerRuntime) Available(ctx context.Context) bool {
+	_, err := m.client(ctx)
+	return err == nil
+}
+
+// Images возвращает список всех docker-образов на хосте.
 //
-// @return срез указателей на DockerImage
-func GetAllDockerImages() []*DockerImage {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// @param ctx контекст операции
+// @return срез указателей на DockerImage и ошибка (если есть)
+func (m *DockerRuntime) Images(ctx context.Context) ([]*DockerImage, error) {
+	cli, err := m.client(ctx)
 
 	if err != nil {
-		log.Fatalf("Ошибка создания Docker-клиента: %v", err)
+		return nil, err
 	}
 
-	images, err := cli.ImageList(context.Background(), image.ListOptions{})
+	images, err := cli.ImageList(ctx, image.ListOptions{})
 
 	if err != nil {
-		log.Fatalf("Ошибка получения списка образов: %v", err)
+		return nil, fmt.Errorf("ошибка получения списка образов: %v", err)
 	}
 
 	imgs := make([]*DockerImage, len(images))
 
 	for i, img := range images {
-		imgs[i] = &DockerImage{
-			Id:   0,
-			Name: img.RepoTags[0],
-			Size: float64(img.Size),
-			Hash: img.ID,
-		}
+		imgs[i] = toDockerImage(img)
+	}
+
+	return imgs, nil
+}
+
+// toDockerImage преобразует описание образа из Docker API в DockerImage.
+// Образы без тегов (dangling) получают имя вида "<none>:<none>", как в docker images.
+//
+// @param img описание образа
+// @return указатель на DockerImage
+func toDockerImage(img image.Summary) *DockerImage {
+	name := "<none>:<none>"
+
+	if len(img.RepoTags) > 0 {
+		name = img.RepoTags[0]
+	} else if len(img.RepoDigests) > 0 {
+		name = strings.SplitN(img.RepoDigests[0], "@", 2)[0] + ":<none>"
+	}
+
+	return &DockerImage{
+		Id:      hashId(img.ID),
+		Name:    name,
+		Tags:    append([]string{}, img.RepoTags...),
+		Digests: append([]string{}, img.RepoDigests...),
+		Size:    float64(img.Size),
+		Hash:    img.ID,
+	}
+}
+
+// hashId вычисляет числовой идентификатор по хешу docker-объекта.
+// Используются первые 7 шестнадцатеричных символов (как в коротком ID),
+// поэтому значение стабильно между запусками и помещается в int на 32-битных платформах.
+//
+// @param hash хеш объекта (с префиксом "sha256:" или без него)
+// @return числовой идентификатор или 0, если хеш некорректен
+func hashId(hash string) int {
+	hash = strings.TrimPrefix(hash, "sha256:")
+
+	if len(hash) > 7 {
+		hash = hash[:7]
 	}
 
-	return imgs
+	id, err := strconv.ParseInt(hash, 16, 64)
+
+	if err != nil {
+		return 0
+	}
+
+	return int(id)
 }
 
-// GetAllDockerContainers возвращает список всех docker-контейнеров на хосте.
+// Containers возвращает список всех docker-контейнеров на хосте, включая остановленные.
 //
-// @return срез указателей на DockerContainer
-func GetAllDockerContainers() []*DockerContainer {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// @param ctx контекст операции
+// @return срез указателей на DockerContainer и ошибка (если есть)
+func (m *DockerRuntime) Containers(ctx context.Context) ([]*DockerContainer, error) {
+	cli, err := m.client(ctx)
 
 	if err != nil {
-		log.Fatalf("Ошибка создания Docker-клиента: %v", err)
+		return nil, err
 	}
 
-	containers, err := cli.ContainerList(context.Background(), container.ListOptions{})
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true})
 
 	if err != nil {
-		log.Fatalf("Ошибка получения списка контейнеров: %v", err)
+		return nil, fmt.Errorf("ошибка получения списка контейнеров: %v", err)
 	}
 
 	conts := make([]*DockerContainer, len(containers))
 
 	for i, cont := range containers {
-		conts[i] = &DockerContainer{
-			Id:        0,
-			Name:      strings.Join(cont.Names, ""),
-			ImageId:   0,
-			ImageHash: cont.ImageID,
-			Status:    cont.State,
-			Recourses: "",
-			Hash:      cont.ID,
-		}
+		conts[i] = toDockerContainer(cont)
 	}
 
-	return conts
+	collectResources(ctx, cli, conts)
+	collectState(ctx, cli, conts)
+
+	return conts, nil
 }
 
-// StopDockerContainer останавливает контейнер по его хешу.
+// InspectContainer возвращает контейнер по его хешу.
+// Ресурсы не заполняются: замер статистики занимает секунды, поэтому он выполняется
+// только при получении полного списка контейнеров.
 //
+// @param ctx контекст операции
 // @param containerHash хеш контейнера
-// @return true и пустая строка при успехе, иначе false и сообщение об ошибке
-func StopDockerContainer(containerHash string) (bool, string) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// @return указатель на DockerContainer (nil, если контейнер не найден) и ошибка (если есть)
+func (m *DockerRuntime) InspectContainer(ctx context.Context, containerHash string) (*DockerContainer, error) {
+	cli, err := m.client(ctx)
 
 	if err != nil {
-		log.Fatalf("Ошибка создания Docker-клиента: %v", err)
-		return false, fmt.Sprintf("Ошибка создания Docker-клиента: %v", err)
+		return nil, err
 	}
 
-	err = cli.ContainerStop(context.Background(), containerHash, container.StopOptions{})
+	containers, err := cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("id", containerHash)),
+	})
 
 	if err != nil {
-		log.Fatalf("Ошибка остановки контейнера: %v", err)
-		return false, fmt.Sprintf("Ошибка остановки контейнера: %v", err)
+		return nil, fmt.Errorf("ошибка получения контейнера: %v", err)
+	}
+
+	for _, cont := range containers {
+		if strings.HasPrefix(cont.ID, containerHash) {
+			conts := []*DockerContainer{toDockerContainer(cont)}
+			collectState(ctx, cli, conts)
+			return conts[0], nil
+		}
 	}
 
-	return true, ""
+	return nil, nil
 }
 
-// RemoveDockerContainer удаляет контейнер по его хешу.
+// toDockerContainer преобразует описание контейнера из Docker API в DockerContainer.
 //
+// @param cont описание контейнера
+// @return указатель на DockerContainer
+func toDockerContainer(cont container.Summary) *DockerContainer {
+	return &DockerContainer{
+		Id:        hashId(cont.ID),
+		Name:      strings.Join(cont.Names, ""),
+		ImageId:   hashId(cont.ImageID),
+		ImageHash: cont.ImageID,
+		Status:    cont.State,
+		Recourses: nil,
+		Labels:    cont.Labels,
+		Hash:      cont.ID,
+	}
+}
+
+// Events подписывается на события контейнеров, образов, томов и сетей docker-демона.
+// Подписка действует до отмены ctx или до ошибки, переданной в канал ошибок.
+//
+// @param ctx контекст подписки
+// @return канал событий и канал ошибок
+func (m *DockerRuntime) Events(ctx context.Context) (<-chan RuntimeEvent, <-chan error) {
+	errs := make(chan error, 1)
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		errs <- err
+		return nil, errs
+	}
+
+	messages, streamErrs := cli.Events(ctx, events.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", string(events.ContainerEventType)),
+			filters.Arg("type", string(events.ImageEventType)),
+			filters.Arg("type", string(events.VolumeEventType)),
+			filters.Arg("type", string(events.NetworkEventType)),
+		),
+	})
+
+	out := make(chan RuntimeEvent)
+
+	// Преобразуем события Docker API в RuntimeEvent
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-streamErrs:
+				errs <- err
+				return
+			case msg := <-messages:
+				event := RuntimeEvent{
+					Type:       string(msg.Type),
+					Action:     string(msg.Action),
+					Id:         msg.Actor.ID,
+					Attributes: msg.Actor.Attributes,
+				}
+
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, errs
+}
+
+// StopContainer останавливает контейнер по его хешу.
+//
+// @param ctx контекст операции
 // @param containerHash хеш контейнера
-// @return true и пустая строка при успехе, иначе false и сообщение об ошибке
-func RemoveDockerContainer(containerHash string) (bool, string) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// @param timeout время ожидания остановки в секундах до принудительного завершения (nil — по умолчанию)
+// @return ошибка (если есть)
+func (m *DockerRuntime) StopContainer(ctx context.Context, containerHash string, timeout *int) error {
+	cli, err := m.client(ctx)
 
 	if err != nil {
-		log.Fatalf("Ошибка создания Docker-клиента: %v", err)
-		return false, fmt.Sprintf("Ошибка создания Docker-клиента: %v", err)
+		return err
+	}
+
+	if err := cli.ContainerStop(ctx, containerHash, container.StopOptions{Timeout: timeout}); err != nil {
+		return fmt.Errorf("ошибка остановки контейнера: %v", err)
 	}
 
-	err = cli.ContainerRemove(context.Background(), containerHash, container.RemoveOptions{Force: true})
+	return nil
+}
+
+// RestartContainer перезапускает контейнер по его хешу.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @param timeout время ожидания остановки в секундах до принудительного завершения (nil — по умолчанию)
+// @return ошибка (если есть)
+func (m *DockerRuntime) RestartContainer(ctx context.Context, containerHash string, timeout *int) error {
+	cli, err := m.client(ctx)
 
 	if err != nil {
-		log.Fatalf("Ошибка удаления контейнера: %v", err)
-		return false, fmt.Sprintf("Ошибка удаления контейнера: %v", err)
+		return err
 	}
 
-	return true, ""
+	if err := cli.ContainerRestart(ctx, containerHash, container.StopOptions{Timeout: timeout}); err != nil {
+		return fmt.Errorf("ошибка перезапуска контейнера: %v", err)
+	}
+
+	return nil
 }
 
-// RemoveDockerImage удаляет docker-образ по его хешу.
+// PauseContainer приостанавливает все процессы контейнера.
 //
-// @param imageHash хеш образа
-// @return true и пустая строка при успехе, иначе false и сообщение об ошибке
-func RemoveDockerImage(imageHash string) (bool, string) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return ошибка (если есть)
+func (m *DockerRuntime) PauseContainer(ctx context.Context, containerHash string) error {
+	cli, err := m.client(ctx)
 
 	if err != nil {
-		log.Fatalf("Ошибка создания Docker-клиента: %v", err)
-		return false, fmt.Sprintf("Ошибка создания Docker-клиента: %v", err)
+		return err
 	}
 
-	_, err = cli.ImageRemove(context.Background(), imageHash, image.RemoveOptions{Force: true})
+	if err := cli.ContainerPause(ctx, containerHash); err != nil {
+		return fmt.Errorf("ошибка приостановки контейнера: %v", err)
+	}
+
+	return nil
+}
+
+// UnpauseContainer возобновляет работу приостановленного контейнера.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return ошибка (если есть)
+func (m *DockerRuntime) UnpauseContainer(ctx context.Context, containerHash string) error {
+	cli, err := m.client(ctx)
 
 	if err != nil {
-		log.Fatalf("Ошибка удаления образа: %v", err)
-		return false, fmt.Sprintf("Ошибка удаления образа: %v", err)
+		return err
 	}
 
-	return true, ""
+	if err := cli.ContainerUnpause(ctx, containerHash); err != nil {
+		return fmt.Errorf("ошибка возобновления контейнера: %v", err)
+	}
+
+	return nil
 }
 
-// StartDockerContainer запускает контейнер по его хешу.
+// KillContainer отправляет сигнал главному процессу контейнера.
 //
+// @param ctx контекст операции
 // @param containerHash хеш контейнера
-// @return true и пустая строка при успехе, иначе false и сообщение об ошибке
-func StartDockerContainer(containerHash string) (bool, string) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// @param signal сигнал (например, SIGKILL, SIGHUP или 9)
+// @return ошибка (если есть)
+func (m *DockerRuntime) KillContainer(ctx context.Context, containerHash string, signal string) error {
+	cli, err := m.client(ctx)
 
 	if err != nil {
-		log.Fatalf("Ошибка создания Docker-клиента: %v", err)
-		return false, fmt.Sprintf("Ошибка создания Docker-клиента: %v", err)
+		return err
+	}
+
+	if err := cli.ContainerKill(ctx, containerHash, signal); err != nil {
+		return fmt.Errorf("ошибка отправки сигнала контейнеру: %v", err)
 	}
 
-	err = cli.ContainerStart(context.Background(), containerHash, container.StartOptions{})
+	return nil
+}
+
+// RemoveContainer удаляет контейнер по его хешу.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return ошибка (если есть)
+func (m *DockerRuntime) RemoveContainer(ctx context.Context, containerHash string) error {
+	cli, err := m.client(ctx)
 
 	if err != nil {
-		log.Fatalf("Ошибка запуска контейнера: %v", err)
-		return false, fmt.Sprintf("Ошибка запуска контейнера: %v", err)
+		return err
+	}
+
+	if err := cli.ContainerRemove(ctx, containerHash, container.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("ошибка удаления контейнера: %v", err)
+	}
+
+	return nil
+}
+
+// RemoveImage удаляет docker-образ по его хешу.
+//
+// @param ctx контекст операции
+// @param imageHash хеш образа
+// @return ошибка (если есть)
+func (m *DockerRuntime) RemoveImage(ctx context.Context, imageHash string) error {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := cli.ImageRemove(ctx, imageHash, image.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("ошибка удаления образа: %v", err)
+	}
+
+	return nil
+}
+
+// StartContainer запускает контейнер по его хешу.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return ошибка (если есть)
+func (m *DockerRuntime) StartContainer(ctx context.Context, containerHash string) error {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if err := cli.ContainerStart(ctx, containerHash, container.StartOptions{}); err != nil {
+		return fmt.Errorf("ошибка запуска контейнера: %v", err)
 	}
 
-	return true, ""
+	return nil
 }
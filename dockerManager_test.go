@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+)
+
+// fakeApiVersion задаёт версию Docker Engine API, которую сообщает поддельный демон.
+const fakeApiVersion = "1.47"
+
+// apiVersionPrefix выделяет префикс версии API из пути запроса.
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9.]+`)
+
+// newFakeDockerApi запускает поддельный Docker Engine API и возвращает работающую с ним DockerRuntime.
+// Обработчики ищутся по строке "МЕТОД /путь" без префикса версии; на /_ping демон отвечает сам,
+// а на неизвестные запросы — 404 с сообщением в формате Docker.
+func newFakeDockerApi(t *testing.T, routes map[string]http.HandlerFunc) *DockerRuntime {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := apiVersionPrefix.ReplaceAllString(r.URL.Path, "")
+		w.Header().Set("Api-Version", fakeApiVersion)
+
+		if path == "/_ping" {
+			_, _ = w.Write([]byte("OK"))
+			return
+		}
+
+		if handler, ok := routes[r.Method+" "+path]; ok {
+			handler(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "no such endpoint: " + r.Method + " " + path})
+	}))
+	t.Cleanup(srv.Close)
+
+	return newApiRuntime("docker", client.WithHost("tcp://"+strings.TrimPrefix(srv.URL, "http://")))
+}
+
+// writeJson возвращает обработчик, отвечающий value в JSON.
+func writeJson(value any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(value)
+	}
+}
+
+const (
+	runningHash = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	exitedHash  = "fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+	imageHash   = "sha256:abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789"
+)
+
+func TestDockerContainersIncludeStopped(t *testing.T) {
+	var listQuery string
+
+	rt := newFakeDockerApi(t, map[string]http.HandlerFunc{
+		"GET /containers/json": func(w http.ResponseWriter, r *http.Request) {
+			listQuery = r.URL.RawQuery
+			writeJson([]container.Summary{
+				{ID: runningHash, Names: []string{"/web"}, ImageID: imageHash, State: "running"},
+				{ID: exitedHash, Names: []string{"/job"}, ImageID: imageHash, State: "exited"},
+			})(w, r)
+		},
+		"GET /containers/" + runningHash + "/json": writeJson(container.InspectResponse{
+			ContainerJSONBase: &container.ContainerJSONBase{
+				ID:           runningHash,
+				RestartCount: 2,
+				State: &container.State{
+					Status: "running",
+					Health: &container.Health{
+						Status: "unhealthy",
+						Log:    []*container.HealthcheckResult{{Output: "  connection refused\n"}},
+					},
+				},
+				HostConfig: &container.HostConfig{
+					Resources:     container.Resources{Memory: 64 << 20},
+					RestartPolicy: container.RestartPolicy{Name: "always"},
+				},
+			},
+		}),
+		"GET /containers/" + exitedHash + "/json": writeJson(container.InspectResponse{
+			ContainerJSONBase: &container.ContainerJSONBase{
+				ID:         exitedHash,
+				State:      &container.State{Status: "exited"},
+				HostConfig: &container.HostConfig{RestartPolicy: container.RestartPolicy{Name: "no"}},
+			},
+		}),
+		"GET /containers/" + runningHash + "/stats": writeJson(container.StatsResponse{
+			MemoryStats: container.MemoryStats{Usage: 10 << 20, Limit: 64 << 20},
+		}),
+	})
+
+	conts, err := rt.Containers(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(listQuery, "all=1") {
+		t.Errorf("список контейнеров запрошен без all=1: %q", listQuery)
+	}
+
+	if len(conts) != 2 {
+		t.Fatalf("получено %d контейнеров, ожидалось 2", len(conts))
+	}
+
+	byHash := make(map[string]*DockerContainer)
+
+	for _, cont := range conts {
+		byHash[cont.Hash] = cont
+	}
+
+	running, exited := byHash[runningHash], byHash[exitedHash]
+
+	if running == nil || exited == nil {
+		t.Fatalf("в списке нет запущенного или остановленного контейнера: %v", byHash)
+	}
+
+	if running.Id != 0x0123456 || running.ImageId != 0xabcdef0 || running.ImageHash != imageHash {
+		t.Errorf("неверные идентификаторы: Id=%x ImageId=%x ImageHash=%s", running.Id, running.ImageId, running.ImageHash)
+	}
+
+	if running.Status != "running" || running.Name != "/web" {
+		t.Errorf("неверный запущенный контейнер: %+v", running)
+	}
+
+	if running.Health != "unhealthy" || running.HealthOutput != "connection refused" || running.RestartCount != 2 {
+		t.Errorf("неверное состояние из inspect: Health=%q HealthOutput=%q RestartCount=%d",
+			running.Health, running.HealthOutput, running.RestartCount)
+	}
+
+	if running.Limits == nil || running.Limits.Memory != 64<<20 || running.RestartPolicy == nil || running.RestartPolicy.Name != "always" {
+		t.Errorf("неверные настройки из inspect: Limits=%+v RestartPolicy=%+v", running.Limits, running.RestartPolicy)
+	}
+
+	if running.Recourses == nil || running.Recourses.MemoryUsage != 10<<20 {
+		t.Errorf("ресурсы запущенного контейнера не заполнены: %+v", running.Recourses)
+	}
+
+	if exited.Status != "exited" || exited.Id != 0xfedcba9 {
+		t.Errorf("неверный остановленный контейнер: %+v", exited)
+	}
+
+	if exited.Recourses != nil {
+		t.Errorf("у остановленного контейнера не должно быть ресурсов: %+v", exited.Recourses)
+	}
+}
+
+func TestDockerImagesIncludeUntagged(t *testing.T) {
+	danglingHash := "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	digestHash := "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+
+	rt := newFakeDockerApi(t, map[string]http.HandlerFunc{
+		"GET /images/json": writeJson([]image.Summary{
+			{
+				ID:          imageHash,
+				RepoTags:    []string{"nginx:1.27", "nginx:latest"},
+				RepoDigests: []string{"nginx@sha256:aaaa"},
+				Size:        1024,
+			},
+			{ID: danglingHash},
+			{ID: digestHash, RepoDigests: []string{"registry:5000/app@sha256:bbbb"}},
+		}),
+	})
+
+	imgs, err := rt.Images(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(imgs) != 3 {
+		t.Fatalf("получено %d образов, ожидалось 3", len(imgs))
+	}
+
+	tagged, dangling, digestOnly := imgs[0], imgs[1], imgs[2]
+
+	if tagged.Name != "nginx:1.27" || len(tagged.Tags) != 2 || tagged.Tags[1] != "nginx:latest" {
+		t.Errorf("неверные теги образа: %+v", tagged)
+	}
+
+	if len(tagged.Digests) != 1 || tagged.Digests[0] != "nginx@sha256:aaaa" {
+		t.Errorf("неверные дайджесты образа: %v", tagged.Digests)
+	}
+
+	if tagged.Id != 0xabcdef0 || tagged.Hash != imageHash || tagged.Size != 1024 {
+		t.Errorf("неверные идентификаторы образа: %+v", tagged)
+	}
+
+	if dangling.Name != "<none>:<none>" || len(dangling.Tags) != 0 || dangling.Id != 0x1111111 {
+		t.Errorf("неверный образ без тегов: %+v", dangling)
+	}
+
+	if digestOnly.Name != "registry:5000/app:<none>" || len(digestOnly.Digests) != 1 {
+		t.Errorf("неверный образ только с дайджестом: %+v", digestOnly)
+	}
+}
+
+func TestHashId(t *testing.T) {
+	tests := map[string]int{
+		imageHash:  0xabcdef0,
+		"abc":      0xabc,
+		"":         0,
+		"not-hash": 0,
+	}
+
+	for hash, want := range tests {
+		if got := hashId(hash); got != want {
+			t.Errorf("hashId(%q) = %x, ожидалось %x", hash, got, want)
+		}
+	}
+}
+
+func TestDockerContainersLimitInspectConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	active, peak := 0, 0
+	var summaries []container.Summary
+	routes := make(map[string]http.HandlerFunc)
+
+	for i := range 3 * inspectConcurrency {
+		hash := fmt.Sprintf("%064x", i+1)
+		summaries = append(summaries, container.Summary{ID: hash, Names: []string{fmt.Sprintf("/c%d", i)}, State: "exited"})
+
+		routes["GET /containers/"+hash+"/json"] = func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			active++
+			peak = max(peak, active)
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			active--
+			mu.Unlock()
+
+			writeJson(container.InspectResponse{
+				ContainerJSONBase: &container.ContainerJSONBase{ID: hash, RestartCount: 1, State: &container.State{Status: "exited"}},
+			})(w, r)
+		}
+	}
+
+	routes["GET /containers/json"] = writeJson(summaries)
+	rt := newFakeDockerApi(t, routes)
+
+	conts, err := rt.Containers(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cont := range conts {
+		if cont.stateUnknown || cont.RestartCount != 1 {
+			t.Fatalf("состояние контейнера не получено: %+v", cont)
+		}
+	}
+
+	if peak > inspectConcurrency {
+		t.Errorf("одновременно выполнялось %d запросов inspect, допустимо не более %d", peak, inspectConcurrency)
+	}
+}
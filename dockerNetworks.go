@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/api/types/network"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// handleRemoveNetwork обрабатывает команду удаления сети.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит хеш или имя сети
+// @return указатель на CommandResult
+func handleRemoveNetwork(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	return errorResult(c.Runtime.RemoveNetwork(ctx, strings.TrimSpace(message.Data)))
+}
+
+// handlePruneNetworks обрабатывает команду удаления неиспользуемых сетей.
+var handlePruneNetworks = pruneCommand(ContainerRuntime.PruneNetworks)
+
+// Networks возвращает список всех docker-сетей с подключёнными контейнерами.
+// Список сетей в Docker API не содержит контейнеров, поэтому каждая сеть запрашивается отдельно.
+//
+// @param ctx контекст операции
+// @return срез указателей на DockerNetwork и ошибка (если есть)
+func (m *DockerRuntime) Networks(ctx context.Context) ([]*DockerNetwork, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	networks, err := cli.NetworkList(ctx, network.ListOptions{})
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка сетей: %v", err)
+	}
+
+	nets := make([]*DockerNetwork, 0, len(networks))
+
+	for _, net := range networks {
+		info, err := cli.NetworkInspect(ctx, net.ID, network.InspectOptions{})
+
+		// Сеть могла быть удалена между получением списка и запросом
+		if err != nil {
+			continue
+		}
+
+		nets = append(nets, toDockerNetwork(info))
+	}
+
+	return nets, nil
+}
+
+// toDockerNetwork преобразует описание сети из Docker API в DockerNetwork.
+//
+// @param net описание сети
+// @return указатель на DockerNetwork
+func toDockerNetwork(net network.Inspect) *DockerNetwork {
+	return &DockerNetwork{
+		Id:         hashId(net.ID),
+		Name:       net.Name,
+		Driver:     net.Driver,
+		Scope:      net.Scope,
+		Internal:   net.Internal,
+		Labels:     net.Labels,
+		Containers: slices.Sorted(maps.Keys(net.Containers)),
+		Hash:       net.ID,
+	}
+}
+
+// RemoveNetwork удаляет сеть по хешу или имени.
+//
+// @param ctx контекст операции
+// @param networkHash хеш или имя сети
+// @return ошибка (если есть)
+func (m *DockerRuntime) RemoveNetwork(ctx context.Context, networkHash string) error {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if err := cli.NetworkRemove(ctx, networkHash); err != nil {
+		return fmt.Errorf("ошибка удаления сети: %v", err)
+	}
+
+	return nil
+}
+
+// PruneNetworks удаляет сети, к которым не подключён ни один контейнер.
+//
+// @param ctx контекст операции
+// @param params параметры удаления
+// @return указатель на PruneReport и ошибка (если есть)
+func (m *DockerRuntime) PruneNetworks(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := cli.NetworksPrune(ctx, pruneFilters(params))
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка удаления неиспользуемых сетей: %v", err)
+	}
+
+	return &PruneReport{Deleted: report.NetworksDeleted}, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/volume"
+	"strings"
+)
+
+// handleRemoveVolume обрабатывает команду удаления тома.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит имя тома
+// @return указатель на CommandResult
+func handleRemoveVolume(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	return errorResult(c.Runtime.RemoveVolume(ctx, strings.TrimSpace(message.Data)))
+}
+
+// handlePruneVolumes обрабатывает команду удаления неиспользуемых томов.
+var handlePruneVolumes = pruneCommand(func(rt ContainerRuntime, ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	if params.Until != "" {
+		return nil, fmt.Errorf("фильтр Until не поддерживается для томов")
+	}
+
+	return rt.PruneVolumes(ctx, params)
+})
+
+// Volumes возвращает список всех docker-томов.
+// Размер томов не запрашивается: подсчёт занятого места обходит их содержимое и может быть долгим,
+// поэтому Size и RefCount равны -1, а размеры возвращает VolumeSizes.
+//
+// @param ctx контекст операции
+// @return срез указателей на DockerVolume и ошибка (если есть)
+func (m *DockerRuntime) Volumes(ctx context.Context) ([]*DockerVolume, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := cli.VolumeList(ctx, volume.ListOptions{})
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка томов: %v", err)
+	}
+
+	vols := make([]*DockerVolume, len(list.Volumes))
+
+	for i, vol := range list.Volumes {
+		vols[i] = toDockerVolume(vol)
+	}
+
+	return vols, nil
+}
+
+// VolumeSizes возвращает список всех docker-томов с занимаемым ими местом
+// из отчёта об использовании диска, ограниченного томами.
+//
+// @param ctx контекст операции
+// @return срез указателей на DockerVolume и ошибка (если есть)
+func (m *DockerRuntime) VolumeSizes(ctx context.Context) ([]*DockerVolume, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := cli.DiskUsage(ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}})
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения размеров томов: %v", err)
+	}
+
+	vols := make([]*DockerVolume, len(usage.Volumes))
+
+	for i, vol := range usage.Volumes {
+		vols[i] = toDockerVolume(vol)
+	}
+
+	return vols, nil
+}
+
+// toDockerVolume преобразует описание тома из Docker API в DockerVolume.
+//
+// @param vol описание тома
+// @return указатель на DockerVolume
+func toDockerVolume(vol *volume.Volume) *DockerVolume {
+	result := &DockerVolume{
+		Name:       vol.Name,
+		Driver:     vol.Driver,
+		Mountpoint: vol.Mountpoint,
+		Scope:      vol.Scope,
+		Labels:     vol.Labels,
+		Size:       -1,
+		RefCount:   -1,
+	}
+
+	if vol.UsageData != nil {
+		result.Size = vol.UsageData.Size
+		result.RefCount = vol.UsageData.RefCount
+	}
+
+	return result
+}
+
+// RemoveVolume удаляет том по имени.
+//
+// @param ctx контекст операции
+// @param name имя тома
+// @return ошибка (если есть)
+func (m *DockerRuntime) RemoveVolume(ctx context.Context, name string) error {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if err := cli.VolumeRemove(ctx, name, false); err != nil {
+		return fmt.Errorf("ошибка удаления тома: %v", err)
+	}
+
+	return nil
+}
+
+// PruneVolumes удаляет неиспользуемые тома. Без All удаляются только анонимные тома.
+//
+// @param ctx контекст операции
+// @param params параметры удаления
+// @return указатель на PruneReport и ошибка (если есть)
+func (m *DockerRuntime) PruneVolumes(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	args := pruneFilters(params)
+
+	if params.All {
+		args.Add("all", "true")
+	}
+
+	report, err := cli.VolumesPrune(ctx, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка удаления неиспользуемых томов: %v", err)
+	}
+
+	return &PruneReport{Deleted: report.VolumesDeleted, SpaceReclaimed: report.SpaceReclaimed}, nil
+}
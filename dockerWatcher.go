@@ -0,0 +1,534 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"maps"
+	"slices"
+	"time"
+)
+
+// containerActions содержит действия над контейнером, после которых меняется его состояние.
+var containerActions = map[string]bool{
+	"create":  true,
+	"start":   true,
+	"restart": true,
+	"stop":    true,
+	"die":     true,
+	"kill":    true,
+	"pause":   true,
+	"unpause": true,
+	"rename":  true,
+	"update":  true,
+	"oom":     true,
+	"destroy": true,
+
+	// Изменения состояния проверки HEALTHCHECK
+	"health_status: starting":  true,
+	"health_status: healthy":   true,
+	"health_status: unhealthy": true,
+}
+
+// imageActions содержит действия над образами, после которых меняется список образов.
+var imageActions = map[string]bool{
+	"pull":   true,
+	"tag":    true,
+	"untag":  true,
+	"load":   true,
+	"import": true,
+	"delete": true,
+}
+
+// volumeActions содержит действия над томами, после которых меняется список томов.
+var volumeActions = map[string]bool{
+	"create":  true,
+	"destroy": true,
+}
+
+// networkActions содержит действия над сетями, после которых меняется список сетей или их контейнеры.
+var networkActions = map[string]bool{
+	"create":     true,
+	"destroy":    true,
+	"remove":     true,
+	"connect":    true,
+	"disconnect": true,
+}
+
+// DockerWatcher отслеживает изменения контейнеров и образов по событиям среды выполнения
+// и отправляет серверу соответствующие события. Для защиты от пропущенных событий
+// периодически выполняет полную сверку состояния.
+type DockerWatcher struct {
+	com        *Communicator               // Communicator для отправки событий.
+	runtime    ContainerRuntime            // Среда выполнения контейнеров.
+	interval   time.Duration               // Интервал полной сверки состояния.
+	containers map[string]*DockerContainer // Известные контейнеры по хешу.
+	images     map[string]*DockerImage     // Известные образы по хешу.
+	projects   map[string]*ComposeProject  // Известные проекты docker compose по имени.
+	volumes    map[string]*DockerVolume    // Известные тома по имени.
+	networks   map[string]*DockerNetwork   // Известные сети по хешу.
+}
+
+// NewDockerWatcher создает новый экземпляр DockerWatcher.
+//
+// @param c указатель на Communicator
+// @param rt среда выполнения контейнеров
+// @param interval интервал полной сверки состояния
+// @return указатель на DockerWatcher
+func NewDockerWatcher(c *Communicator, rt ContainerRuntime, interval time.Duration) *DockerWatcher {
+	return &DockerWatcher{
+		com:      c,
+		runtime:  rt,
+		interval: interval,
+	}
+}
+
+// load загружает начальное состояние среды выполнения. Объекты, существующие при запуске,
+// событиями не отправляются: сервер получает их в стартовом сообщении.
+//
+// @param ctx контекст операции
+func (w *DockerWatcher) load(ctx context.Context) {
+	w.containers = make(map[string]*DockerContainer)
+	w.images = make(map[string]*DockerImage)
+	w.volumes = make(map[string]*DockerVolume)
+	w.networks = make(map[string]*DockerNetwork)
+
+	if containers, err := w.runtime.Containers(ctx); err == nil {
+		for _, ctr := range containers {
+			w.containers[ctr.Hash] = ctr
+		}
+	}
+
+	w.projects = groupComposeProjects(slices.Collect(maps.Values(w.containers)))
+
+	if images, err := w.runtime.Images(ctx); err == nil {
+		for _, img := range images {
+			w.images[img.Hash] = img
+		}
+	}
+
+	if volumes, err := w.runtime.Volumes(ctx); err == nil {
+		for _, vol := range volumes {
+			w.volumes[vol.Name] = vol
+		}
+	}
+
+	if networks, err := w.runtime.Networks(ctx); err == nil {
+		for _, net := range networks {
+			w.networks[net.Hash] = net
+		}
+	}
+}
+
+// Run запускает отслеживание. Метод блокирует вызывающую горутину: подписывается на события
+// среды выполнения, при обрыве подписки переподписывается с экспоненциальной задержкой
+// и после каждой подписки сверяет состояние, чтобы не пропустить изменения во время разрыва.
+func (w *DockerWatcher) Run() {
+	w.load(context.Background())
+
+	backoff := NewBackoff(time.Second, time.Minute)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		messages, errs := w.runtime.Events(ctx)
+		w.resync()
+
+		err := w.consume(messages, errs, ticker.C, backoff)
+		cancel()
+
+		delay := backoff.Next()
+		log.Printf("Подписка на события среды выполнения прервана: %v, повтор через %v", err, delay)
+		time.Sleep(delay)
+	}
+}
+
+// consume обрабатывает события среды выполнения и периодическую сверку до ошибки подписки.
+//
+// @param messages канал событий
+// @param errs канал ошибок подписки
+// @param resync канал таймера полной сверки
+// @param backoff задержка переподписки, сбрасываемая при получении событий
+// @return ошибка, из-за которой подписка была прервана
+func (w *DockerWatcher) consume(messages <-chan RuntimeEvent, errs <-chan error, resync <-chan time.Time, backoff *Backoff) error {
+	for {
+		select {
+		case msg := <-messages:
+			backoff.Reset()
+			w.handleEvent(msg)
+		case err := <-errs:
+			return err
+		case <-resync:
+			w.resync()
+		}
+	}
+}
+
+// handleEvent обрабатывает одно событие среды выполнения.
+//
+// @param msg событие среды выполнения
+func (w *DockerWatcher) handleEvent(msg RuntimeEvent) {
+	switch msg.Type {
+	case EventContainer:
+		if containerActions[msg.Action] {
+			w.refreshContainer(msg)
+		}
+	case EventImage:
+		if imageActions[msg.Action] {
+			w.resyncImages()
+		}
+	case EventVolume:
+		if volumeActions[msg.Action] {
+			w.resyncVolumes()
+		}
+	case EventNetwork:
+		if networkActions[msg.Action] {
+			w.resyncNetworks()
+		}
+	}
+}
+
+// refreshContainer обновляет состояние одного контейнера после события.
+// Ресурсы контейнера здесь не замеряются, чтобы серия событий (например, запуск проекта
+// docker compose) не задерживалась на секунды на каждом контейнере: они сохраняются
+// с прошлого замера и обновляются при полной сверке.
+//
+// @param msg событие контейнера
+func (w *DockerWatcher) refreshContainer(msg RuntimeEvent) {
+	var ctr *DockerContainer
+	hash := msg.Id
+
+	if msg.Action != "destroy" {
+		var err error
+		ctr, err = w.runtime.InspectContainer(context.Background(), hash)
+
+		if err != nil {
+			log.Printf("Ошибка обновления контейнера %s: %v", hash, err)
+			return
+		}
+	}
+
+	prev, known := w.containers[hash]
+
+	if ctr != nil && known {
+		carryState(prev, ctr)
+
+		if ctr.Status == "running" {
+			ctr.Recourses = prev.Recourses
+		}
+	}
+
+	// Короткоживущий контейнер может быть удалён до того, как обработано событие о его создании,
+	// поэтому сведения о нём берутся из самого события
+	if ctr == nil && !known {
+		switch msg.Action {
+		case "create":
+			ctr = w.containerFromEvent(msg, "created")
+		case "destroy":
+			w.emit(RemovedDockerContainer, w.containerFromEvent(msg, "removing"))
+			return
+		}
+	}
+
+	switch {
+	case ctr == nil && known:
+		delete(w.containers, hash)
+		w.emit(RemovedDockerContainer, prev)
+	case ctr != nil && !known:
+		w.containers[hash] = ctr
+		w.emit(AddedDockerContainer, ctr)
+		w.emitAlerts(nil, ctr)
+	case ctr != nil && containerChanged(prev, ctr):
+		w.containers[hash] = ctr
+		w.emit(UpdatedDockerContainer, ctr)
+		w.emitAlerts(prev, ctr)
+	default:
+		return
+	}
+
+	w.resyncProjects()
+}
+
+// containerFromEvent восстанавливает описание контейнера по атрибутам события,
+// когда получить его из среды выполнения уже нельзя.
+//
+// @param msg событие контейнера
+// @param status состояние контейнера
+// @return указатель на DockerContainer
+func (w *DockerWatcher) containerFromEvent(msg RuntimeEvent, status string) *DockerContainer {
+	ctr := &DockerContainer{
+		Id:     hashId(msg.Id),
+		Name:   "/" + msg.Attributes["name"],
+		Status: status,
+		Labels: make(map[string]string),
+		Hash:   msg.Id,
+	}
+
+	// Кроме имени и образа атрибуты событий create и destroy содержат метки контейнера
+	for key, value := range msg.Attributes {
+		if key != "name" && key != "image" {
+			ctr.Labels[key] = value
+		}
+	}
+
+	image := msg.Attributes["image"]
+
+	for hash, img := range w.images {
+		if hash == image || slices.Contains(img.Tags, image) {
+			ctr.ImageId = img.Id
+			ctr.ImageHash = img.Hash
+			break
+		}
+	}
+
+	return ctr
+}
+
+// resync выполняет полную сверку контейнеров, образов, томов и сетей с известным состоянием.
+func (w *DockerWatcher) resync() {
+	w.resyncContainers()
+	w.resyncImages()
+	w.resyncVolumes()
+	w.resyncNetworks()
+}
+
+// resyncContainers сверяет список контейнеров и отправляет события об изменениях.
+// Если среда выполнения недоступна, известное состояние сохраняется без изменений.
+func (w *DockerWatcher) resyncContainers() {
+	containers, err := w.runtime.Containers(context.Background())
+
+	if err != nil {
+		// О недоступности среды выполнения уже сообщает её реализация
+		if !errors.Is(err, errRuntimeUnavailable) {
+			log.Printf("Сверка контейнеров пропущена: %v", err)
+		}
+
+		return
+	}
+
+	curr := make(map[string]*DockerContainer)
+
+	for _, ctr := range containers {
+		curr[ctr.Hash] = ctr
+	}
+
+	// Check for added, updated containers
+	for id, ctr := range curr {
+		prev, ok := w.containers[id]
+
+		if ok {
+			carryState(prev, ctr)
+		}
+
+		if !ok {
+			w.emit(AddedDockerContainer, ctr)
+			w.emitAlerts(nil, ctr)
+		} else if containerChanged(prev, ctr) {
+			w.emit(UpdatedDockerContainer, ctr)
+			w.emitAlerts(prev, ctr)
+		} else {
+			// Сохраняем последнее отправленное состояние, чтобы медленный дрейф ресурсов
+			// сравнивался с ним, а не с предыдущим замером
+			curr[id] = prev
+		}
+	}
+
+	// Check for removed containers
+	for id, ctr := range w.containers {
+		if _, ok := curr[id]; !ok {
+			w.emit(RemovedDockerContainer, ctr)
+		}
+	}
+
+	w.containers = curr
+	w.resyncProjects()
+}
+
+// resyncProjects пересчитывает проекты docker compose по известным контейнерам
+// и отправляет события об изменениях.
+func (w *DockerWatcher) resyncProjects() {
+	curr := groupComposeProjects(slices.Collect(maps.Values(w.containers)))
+
+	for name, project := range curr {
+		prev, ok := w.projects[name]
+
+		if !ok {
+			w.emit(AddedComposeProject, project)
+		} else if composeProjectChanged(prev, project) {
+			w.emit(UpdatedComposeProject, project)
+		}
+	}
+
+	for name, project := range w.projects {
+		if _, ok := curr[name]; !ok {
+			w.emit(RemovedComposeProject, project)
+		}
+	}
+
+	w.projects = curr
+}
+
+// resyncImages сверяет список образов и отправляет события об изменениях.
+// Если среда выполнения недоступна, известное состояние сохраняется без изменений.
+func (w *DockerWatcher) resyncImages() {
+	images, err := w.runtime.Images(context.Background())
+
+	if err != nil {
+		// О недоступности среды выполнения уже сообщает её реализация
+		if !errors.Is(err, errRuntimeUnavailable) {
+			log.Printf("Сверка образов пропущена: %v", err)
+		}
+
+		return
+	}
+
+	curr := make(map[string]*DockerImage)
+
+	for _, img := range images {
+		curr[img.Hash] = img
+	}
+
+	// Check for added images
+	for id, img := range curr {
+		if _, ok := w.images[id]; !ok {
+			w.emit(AddedDockerImage, img)
+		}
+	}
+
+	// Check for removed images
+	for id, img := range w.images {
+		if _, ok := curr[id]; !ok {
+			w.emit(RemovedDockerImage, img)
+		}
+	}
+
+	w.images = curr
+}
+
+// resyncVolumes сверяет список томов и отправляет события об изменениях.
+// Размер тома в списке не запрашивается, поэтому его изменение событием не считается.
+func (w *DockerWatcher) resyncVolumes() {
+	volumes, err := w.runtime.Volumes(context.Background())
+
+	if err != nil {
+		if !errors.Is(err, errRuntimeUnavailable) {
+			log.Printf("Сверка томов пропущена: %v", err)
+		}
+
+		return
+	}
+
+	curr := make(map[string]*DockerVolume)
+
+	for _, vol := range volumes {
+		curr[vol.Name] = vol
+	}
+
+	for name, vol := range curr {
+		if _, ok := w.volumes[name]; !ok {
+			w.emit(AddedDockerVolume, vol)
+		}
+	}
+
+	for name, vol := range w.volumes {
+		if _, ok := curr[name]; !ok {
+			w.emit(RemovedDockerVolume, vol)
+		}
+	}
+
+	w.volumes = curr
+}
+
+// resyncNetworks сверяет список сетей и отправляет события об изменениях,
+// в том числе о подключении и отключении контейнеров.
+func (w *DockerWatcher) resyncNetworks() {
+	networks, err := w.runtime.Networks(context.Background())
+
+	if err != nil {
+		if !errors.Is(err, errRuntimeUnavailable) {
+			log.Printf("Сверка сетей пропущена: %v", err)
+		}
+
+		return
+	}
+
+	curr := make(map[string]*DockerNetwork)
+
+	for _, net := range networks {
+		curr[net.Hash] = net
+	}
+
+	for id, net := range curr {
+		prev, ok := w.networks[id]
+
+		if !ok {
+			w.emit(AddedDockerNetwork, net)
+		} else if prev.Name != net.Name || !slices.Equal(prev.Containers, net.Containers) {
+			w.emit(UpdatedDockerNetwork, net)
+		}
+	}
+
+	for id, net := range w.networks {
+		if _, ok := curr[id]; !ok {
+			w.emit(RemovedDockerNetwork, net)
+		}
+	}
+
+	w.networks = curr
+}
+
+// emit сохраняет событие в очередь отправки Communicator.
+//
+// @param messageType тип исходящего сообщения
+// @param value объект, к которому относится событие
+func (w *DockerWatcher) emit(messageType TypeSentMessage, value any) {
+	data, _ := json.Marshal(value)
+	w.com.Push(&SentMessage{Type: messageType, Data: string(data)})
+}
+
+// emitAlerts отправляет отдельные события о переходе контейнера в unhealthy
+// и о его перезапуске средой выполнения, чтобы сервер мог оповестить о них без опроса.
+//
+// @param prev предыдущее состояние контейнера (nil для нового контейнера)
+// @param curr текущее состояние контейнера
+func (w *DockerWatcher) emitAlerts(prev *DockerContainer, curr *DockerContainer) {
+	if becameUnhealthy(prev, curr) {
+		w.emit(ContainerUnhealthy, curr)
+	}
+
+	if restartedByRuntime(prev, curr) {
+		w.emit(ContainerRestarted, curr)
+	}
+}
+
+// containerChanged сообщает, изменилось ли состояние контейнера.
+// Вывод проверки HEALTHCHECK не учитывается, так как меняется при каждой проверке.
+//
+// @param prev предыдущее состояние контейнера
+// @param curr текущее состояние контейнера
+// @return true, если изменились статус, имя, состояние проверки, число перезапусков, ограничения ресурсов,
+// политика перезапуска или значимо изменились ресурсы
+func containerChanged(prev *DockerContainer, curr *DockerContainer) bool {
+	return prev.Status != curr.Status ||
+		prev.Name != curr.Name ||
+		prev.Health != curr.Health ||
+		prev.RestartCount != curr.RestartCount ||
+		valueChanged(prev.Limits, curr.Limits) ||
+		valueChanged(prev.RestartPolicy, curr.RestartPolicy) ||
+		resourcesChanged(prev.Recourses, curr.Recourses)
+}
+
+// valueChanged сравнивает значения по указателям.
+//
+// @param prev предыдущее значение (может быть nil)
+// @param curr текущее значение (может быть nil)
+// @return true, если значения различаются
+func valueChanged[T comparable](prev *T, curr *T) bool {
+	if prev == nil || curr == nil {
+		return prev != curr
+	}
+
+	return *prev != *curr
+}
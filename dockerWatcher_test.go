@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// newTestCommunicator создает Communicator без соединения со спулом во временном каталоге.
+func newTestCommunicator(t *testing.T, rt ContainerRuntime) *Communicator {
+	t.Helper()
+
+	c, err := NewCommunicator(&Config{Ip: "127.0.0.1:0", SpoolDir: t.TempDir()}, rt)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+// watcherHarness связывает DockerWatcher с FakeRuntime и подпиской на её события.
+type watcherHarness struct {
+	t       *testing.T
+	rt      *FakeRuntime
+	com     *Communicator
+	watcher *DockerWatcher
+	events  <-chan RuntimeEvent
+}
+
+// newWatcherHarness создает DockerWatcher с начальным состоянием rt.
+func newWatcherHarness(t *testing.T, rt *FakeRuntime) *watcherHarness {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	com := newTestCommunicator(t, rt)
+	watcher := NewDockerWatcher(com, rt, 0)
+	watcher.load(ctx)
+	events, _ := rt.Events(ctx)
+
+	return &watcherHarness{t: t, rt: rt, com: com, watcher: watcher, events: events}
+}
+
+// process передаёт наблюдателю накопившиеся события и возвращает типы отправленных им сообщений.
+func (h *watcherHarness) process() []TypeSentMessage {
+	h.t.Helper()
+
+	for {
+		select {
+		case msg := <-h.events:
+			h.watcher.handleEvent(msg)
+		default:
+			return h.sent()
+		}
+	}
+}
+
+// sent извлекает из спула отправленные наблюдателем сообщения и возвращает их типы.
+func (h *watcherHarness) sent() []TypeSentMessage {
+	h.t.Helper()
+
+	var types []TypeSentMessage
+
+	for {
+		message, err := h.com.Requests.Peek()
+
+		if err != nil {
+			h.t.Fatal(err)
+		}
+
+		if message == nil {
+			return types
+		}
+
+		types = append(types, message.Type)
+
+		if err := h.com.Requests.Ack(); err != nil {
+			h.t.Fatal(err)
+		}
+	}
+}
+
+// expectSent проверяет, что наблюдатель отправил ровно сообщения want в указанном порядке.
+func expectSent(t *testing.T, got []TypeSentMessage, want ...TypeSentMessage) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("отправлены сообщения %v, ожидались %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("отправлены сообщения %v, ожидались %v", got, want)
+		}
+	}
+}
+
+// newTestContainer возвращает контейнер с заданным хешем и состоянием.
+func newTestContainer(hash string, status string) *DockerContainer {
+	return &DockerContainer{Id: hashId(hash), Name: "/" + hash[:4], Status: status, Hash: hash}
+}
+
+func TestWatcherContainerLifecycle(t *testing.T) {
+	h := newWatcherHarness(t, NewFakeRuntime())
+
+	h.rt.AddContainer(newTestContainer(runningHash, "created"))
+	expectSent(t, h.process(), AddedDockerContainer)
+
+	if err := h.rt.StartContainer(context.Background(), runningHash); err != nil {
+		t.Fatal(err)
+	}
+
+	expectSent(t, h.process(), UpdatedDockerContainer)
+
+	if err := h.rt.RemoveContainer(context.Background(), runningHash); err != nil {
+		t.Fatal(err)
+	}
+
+	expectSent(t, h.process(), RemovedDockerContainer)
+}
+
+func TestWatcherReportsShortLivedContainer(t *testing.T) {
+	h := newWatcherHarness(t, NewFakeRuntime())
+
+	// Контейнер удалён до того, как наблюдатель обработал событие о его создании
+	h.rt.AddContainer(newTestContainer(exitedHash, "created"))
+
+	if err := h.rt.RemoveContainer(context.Background(), exitedHash); err != nil {
+		t.Fatal(err)
+	}
+
+	expectSent(t, h.process(), AddedDockerContainer, RemovedDockerContainer)
+}
+
+func TestWatcherAlerts(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.AddContainer(newTestContainer(runningHash, "running"))
+	h := newWatcherHarness(t, rt)
+
+	if err := rt.SetHealth(runningHash, "unhealthy", "connection refused"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectSent(t, h.process(), UpdatedDockerContainer, ContainerUnhealthy)
+
+	// Повторная неудачная проверка не должна вызывать новое оповещение
+	if err := rt.SetHealth(runningHash, "unhealthy", "timeout"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectSent(t, h.process())
+
+	if err := rt.RestartByPolicy(runningHash); err != nil {
+		t.Fatal(err)
+	}
+
+	expectSent(t, h.process(), UpdatedDockerContainer, ContainerRestarted)
+}
+
+func TestWatcherVolumesAndNetworks(t *testing.T) {
+	h := newWatcherHarness(t, NewFakeRuntime())
+
+	h.rt.AddVolume(&DockerVolume{Name: "data", Driver: "local", Size: 4096, RefCount: 0})
+	h.rt.AddNetwork(&DockerNetwork{Id: hashId(exitedHash), Name: "backend", Driver: "bridge", Hash: exitedHash})
+	expectSent(t, h.process(), AddedDockerVolume, AddedDockerNetwork)
+
+	if err := h.rt.RemoveVolume(context.Background(), "data"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.rt.RemoveNetwork(context.Background(), exitedHash); err != nil {
+		t.Fatal(err)
+	}
+
+	expectSent(t, h.process(), RemovedDockerVolume, RemovedDockerNetwork)
+}
+
+func TestWatcherKeepsStateWhileRuntimeUnavailable(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.AddContainer(newTestContainer(runningHash, "running"))
+	rt.AddVolume(&DockerVolume{Name: "data", Driver: "local"})
+	h := newWatcherHarness(t, rt)
+
+	rt.SetAvailable(false)
+	h.watcher.resync()
+
+	// Недоступная среда выполнения не означает, что контейнеры и тома удалены
+	expectSent(t, h.sent())
+
+	rt.SetAvailable(true)
+	h.watcher.resync()
+	expectSent(t, h.sent())
+}
+
+func TestFakeVolumeSizeOnlyInDiskUsage(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.AddVolume(&DockerVolume{Name: "data", Driver: "local", Size: 4096, RefCount: 1})
+
+	vols, err := rt.Volumes(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vols) != 1 || vols[0].Size != -1 || vols[0].RefCount != -1 {
+		t.Fatalf("размер тома не должен передаваться в списке: %+v", vols)
+	}
+
+	report, err := rt.DiskUsage(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.VolumeSizes) != 1 || report.VolumeSizes[0].Size != 4096 || report.Volumes.Active != 1 {
+		t.Fatalf("неверный отчёт об использовании диска томами: %+v", report)
+	}
+}
@@ -0,0 +1,1215 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FakeRuntime реализует ContainerRuntime в памяти для модульных тестов.
+type FakeRuntime struct {
+	mu          sync.Mutex                   // Мьютекс для синхронизации доступа к состоянию.
+	available   bool                         // Отвечает ли среда выполнения.
+	images      map[string]*DockerImage      // Образы по хешу.
+	containers  map[string]*DockerContainer  // Контейнеры по хешу.
+	logs        map[string]string            // Логи контейнеров по хешу.
+	files       map[string]map[string][]byte // Файлы контейнеров по хешу и пути.
+	volumes     map[string]*DockerVolume     // Тома по имени.
+	networks    map[string]*DockerNetwork    // Сети по хешу.
+	subscribers []chan RuntimeEvent          // Каналы подписчиков на события.
+}
+
+// Проверяем, что FakeRuntime удовлетворяет интерфейсу ContainerRuntime.
+var _ ContainerRuntime = (*FakeRuntime)(nil)
+
+// NewFakeRuntime создает пустую доступную среду выполнения в памяти.
+//
+// @return указатель на FakeRuntime
+func NewFakeRuntime() *FakeRuntime {
+	return &FakeRuntime{
+		available:  true,
+		images:     make(map[string]*DockerImage),
+		containers: make(map[string]*DockerContainer),
+		logs:       make(map[string]string),
+		files:      make(map[string]map[string][]byte),
+		volumes:    make(map[string]*DockerVolume),
+		networks:   make(map[string]*DockerNetwork),
+	}
+}
+
+// Name возвращает название среды выполнения.
+//
+// @return название среды выполнения
+func (f *FakeRuntime) Name() string {
+	return "fake"
+}
+
+// SetAvailable имитирует доступность или недоступность среды выполнения.
+//
+// @param available доступна ли среда выполнения
+func (f *FakeRuntime) SetAvailable(available bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.available = available
+}
+
+// AddImage добавляет образ и отправляет подписчикам событие pull.
+//
+// @param img указатель на DockerImage
+func (f *FakeRuntime) AddImage(img *DockerImage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.images[img.Hash] = img
+	f.publish(RuntimeEvent{Type: EventImage, Action: "pull", Id: img.Hash})
+}
+
+// AddContainer добавляет контейнер и отправляет подписчикам событие create.
+//
+// @param ctr указатель на DockerContainer
+func (f *FakeRuntime) AddContainer(ctr *DockerContainer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if ctr.Status == "" {
+		ctr.Status = "created"
+	}
+
+	if ctr.Limits == nil {
+		ctr.Limits = &ResourceLimits{}
+	}
+
+	if ctr.RestartPolicy == nil {
+		ctr.RestartPolicy = &RestartPolicy{Name: "no"}
+	}
+
+	f.containers[ctr.Hash] = ctr
+	f.publish(RuntimeEvent{Type: EventContainer, Action: "create", Id: ctr.Hash, Attributes: containerAttributes(ctr)})
+}
+
+// containerAttributes формирует атрибуты события контейнера так же, как Docker:
+// метки контейнера, имя без ведущего "/" и образ.
+//
+// @param ctr указатель на DockerContainer
+// @return атрибуты события
+func containerAttributes(ctr *DockerContainer) map[string]string {
+	attrs := maps.Clone(ctr.Labels)
+
+	if attrs == nil {
+		attrs = make(map[string]string)
+	}
+
+	attrs["name"] = strings.TrimPrefix(ctr.Name, "/")
+	attrs["image"] = ctr.ImageHash
+
+	return attrs
+}
+
+// SetLogs задаёт логи контейнера, которые вернёт Logs.
+//
+// @param containerHash хеш контейнера
+// @param logs текст логов
+func (f *FakeRuntime) SetLogs(containerHash string, logs string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logs[containerHash] = logs
+}
+
+// AddVolume добавляет том и отправляет подписчикам событие create.
+//
+// @param vol указатель на DockerVolume
+func (f *FakeRuntime) AddVolume(vol *DockerVolume) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.volumes[vol.Name] = vol
+	f.publish(RuntimeEvent{Type: EventVolume, Action: "create", Id: vol.Name})
+}
+
+// AddNetwork добавляет сеть и отправляет подписчикам событие create.
+//
+// @param net указатель на DockerNetwork
+func (f *FakeRuntime) AddNetwork(net *DockerNetwork) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.networks[net.Hash] = net
+	f.publish(RuntimeEvent{Type: EventNetwork, Action: "create", Id: net.Hash})
+}
+
+// Available проверяет, отвечает ли среда выполнения.
+//
+// @param ctx контекст операции
+// @return true, если среда выполнения доступна
+func (f *FakeRuntime) Available(ctx context.Context) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.available
+}
+
+// check возвращает ошибку, если среда выполнения недоступна. Вызывается под мьютексом.
+//
+// @return ошибка, оборачивающая errRuntimeUnavailable
+func (f *FakeRuntime) check() error {
+	if !f.available {
+		return fmt.Errorf("%w: fake", errRuntimeUnavailable)
+	}
+
+	return nil
+}
+
+// Images возвращает копии всех образов.
+//
+// @param ctx контекст операции
+// @return срез указателей на DockerImage и ошибка (если есть)
+func (f *FakeRuntime) Images(ctx context.Context) ([]*DockerImage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	imgs := make([]*DockerImage, 0, len(f.images))
+
+	for _, img := range f.images {
+		copied := *img
+		imgs = append(imgs, &copied)
+	}
+
+	return imgs, nil
+}
+
+// Containers возвращает копии всех контейнеров.
+//
+// @param ctx контекст операции
+// @return срез указателей на DockerContainer и ошибка (если есть)
+func (f *FakeRuntime) Containers(ctx context.Context) ([]*DockerContainer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	conts := make([]*DockerContainer, 0, len(f.containers))
+
+	for _, ctr := range f.containers {
+		copied := *ctr
+		conts = append(conts, &copied)
+	}
+
+	return conts, nil
+}
+
+// InspectContainer возвращает копию контейнера по хешу или его префиксу.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return указатель на DockerContainer (nil, если контейнер не найден) и ошибка (если есть)
+func (f *FakeRuntime) InspectContainer(ctx context.Context, containerHash string) (*DockerContainer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	ctr := f.find(containerHash)
+
+	if ctr == nil {
+		return nil, nil
+	}
+
+	copied := *ctr
+	return &copied, nil
+}
+
+// find ищет контейнер по хешу или его префиксу. Вызывается под мьютексом.
+//
+// @param containerHash хеш контейнера
+// @return указатель на DockerContainer или nil
+func (f *FakeRuntime) find(containerHash string) *DockerContainer {
+	for hash, ctr := range f.containers {
+		if containerHash != "" && strings.HasPrefix(hash, containerHash) {
+			return ctr
+		}
+	}
+
+	return nil
+}
+
+// setStatus меняет состояние контейнера и отправляет событие.
+//
+// @param containerHash хеш контейнера
+// @param status новое состояние
+// @param action действие для события
+// @return ошибка (если есть)
+func (f *FakeRuntime) setStatus(containerHash string, status string, action string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+
+	ctr := f.find(containerHash)
+
+	if ctr == nil {
+		return fmt.Errorf("no such container: %s", containerHash)
+	}
+
+	ctr.Status = status
+	f.publish(RuntimeEvent{Type: EventContainer, Action: action, Id: ctr.Hash})
+
+	return nil
+}
+
+// SetHealth имитирует результат проверки HEALTHCHECK и отправляет событие health_status.
+//
+// @param containerHash хеш контейнера
+// @param health состояние проверки (starting, healthy, unhealthy)
+// @param output вывод проверки
+// @return ошибка (если есть)
+func (f *FakeRuntime) SetHealth(containerHash string, health string, output string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ctr := f.find(containerHash)
+
+	if ctr == nil {
+		return fmt.Errorf("no such container: %s", containerHash)
+	}
+
+	ctr.Health = health
+	ctr.HealthOutput = output
+	f.publish(RuntimeEvent{Type: EventContainer, Action: "health_status: " + health, Id: ctr.Hash})
+
+	return nil
+}
+
+// RestartByPolicy имитирует перезапуск упавшего контейнера по политике перезапуска.
+//
+// @param containerHash хеш контейнера
+// @return ошибка (если есть)
+func (f *FakeRuntime) RestartByPolicy(containerHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ctr := f.find(containerHash)
+
+	if ctr == nil {
+		return fmt.Errorf("no such container: %s", containerHash)
+	}
+
+	ctr.Status = "running"
+	ctr.RestartCount++
+	f.publish(RuntimeEvent{Type: EventContainer, Action: "die", Id: ctr.Hash})
+	f.publish(RuntimeEvent{Type: EventContainer, Action: "start", Id: ctr.Hash})
+
+	return nil
+}
+
+// StartContainer запускает контейнер.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return ошибка (если есть)
+func (f *FakeRuntime) StartContainer(ctx context.Context, containerHash string) error {
+	return f.setStatus(containerHash, "running", "start")
+}
+
+// StopContainer останавливает контейнер.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @param timeout не используется
+// @return ошибка (если есть)
+func (f *FakeRuntime) StopContainer(ctx context.Context, containerHash string, timeout *int) error {
+	return f.setStatus(containerHash, "exited", "die")
+}
+
+// RestartContainer перезапускает контейнер.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @param timeout не используется
+// @return ошибка (если есть)
+func (f *FakeRuntime) RestartContainer(ctx context.Context, containerHash string, timeout *int) error {
+	return f.setStatus(containerHash, "running", "restart")
+}
+
+// PauseContainer приостанавливает контейнер.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return ошибка (если есть)
+func (f *FakeRuntime) PauseContainer(ctx context.Context, containerHash string) error {
+	return f.setStatus(containerHash, "paused", "pause")
+}
+
+// UnpauseContainer возобновляет работу контейнера.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return ошибка (если есть)
+func (f *FakeRuntime) UnpauseContainer(ctx context.Context, containerHash string) error {
+	return f.setStatus(containerHash, "running", "unpause")
+}
+
+// KillContainer имитирует завершение контейнера сигналом.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @param signal не используется
+// @return ошибка (если есть)
+func (f *FakeRuntime) KillContainer(ctx context.Context, containerHash string, signal string) error {
+	return f.setStatus(containerHash, "exited", "kill")
+}
+
+// RemoveContainer удаляет контейнер.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return ошибка (если есть)
+func (f *FakeRuntime) RemoveContainer(ctx context.Context, containerHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+
+	ctr := f.find(containerHash)
+
+	if ctr == nil {
+		return fmt.Errorf("no such container: %s", containerHash)
+	}
+
+	delete(f.containers, ctr.Hash)
+	f.publish(RuntimeEvent{Type: EventContainer, Action: "destroy", Id: ctr.Hash, Attributes: containerAttributes(ctr)})
+
+	return nil
+}
+
+// RemoveImage удаляет образ.
+//
+// @param ctx контекст операции
+// @param imageHash хеш образа
+// @return ошибка (если есть)
+func (f *FakeRuntime) RemoveImage(ctx context.Context, imageHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+
+	if _, ok := f.images[imageHash]; !ok {
+		return fmt.Errorf("no such image: %s", imageHash)
+	}
+
+	delete(f.images, imageHash)
+	f.publish(RuntimeEvent{Type: EventImage, Action: "delete", Id: imageHash})
+
+	return nil
+}
+
+// Logs записывает заданные через SetLogs логи в stdout.
+// С Follow блокируется до отмены ctx.
+//
+// @param ctx контекст операции
+// @param params параметры чтения логов
+// @param stdout получатель стандартного вывода
+// @param stderr не используется
+// @return ошибка (если есть)
+func (f *FakeRuntime) Logs(ctx context.Context, params *LogsParams, stdout io.Writer, stderr io.Writer) error {
+	f.mu.Lock()
+
+	if err := f.check(); err != nil {
+		f.mu.Unlock()
+		return err
+	}
+
+	ctr := f.find(params.Hash)
+
+	if ctr == nil {
+		f.mu.Unlock()
+		return fmt.Errorf("no such container: %s", params.Hash)
+	}
+
+	logs := f.logs[ctr.Hash]
+	f.mu.Unlock()
+
+	if _, err := io.WriteString(stdout, logs); err != nil {
+		return err
+	}
+
+	if params.Follow {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// Exec имитирует выполнение команды: выводит её в stdout и повторяет ввод до его окончания.
+//
+// @param ctx контекст операции
+// @param params параметры выполнения
+// @param streams потоки ввода-вывода сессии
+// @return код завершения (0) и ошибка (если есть)
+func (f *FakeRuntime) Exec(ctx context.Context, params *ExecParams, streams *ExecStreams) (int, error) {
+	f.mu.Lock()
+
+	if err := f.check(); err != nil {
+		f.mu.Unlock()
+		return -1, err
+	}
+
+	ctr := f.find(params.Hash)
+	f.mu.Unlock()
+
+	if ctr == nil {
+		return -1, fmt.Errorf("no such container: %s", params.Hash)
+	}
+
+	if _, err := io.WriteString(streams.Stdout, strings.Join(params.Cmd, " ")+"\n"); err != nil {
+		return -1, err
+	}
+
+	if streams.Stdin != nil {
+		if _, err := io.Copy(streams.Stdout, streams.Stdin); err != nil {
+			return -1, err
+		}
+	}
+
+	return 0, nil
+}
+
+// PullImage имитирует загрузку образа: сообщает о загрузке одного слоя и добавляет образ.
+// Хеш и дайджест образа вычисляются из ссылки на образ, поэтому повторная загрузка даёт тот же образ.
+//
+// @param ctx контекст операции
+// @param params параметры загрузки
+// @param progress вызывается для каждого сообщения о ходе загрузки
+// @return дайджест образа и ошибка (если есть)
+func (f *FakeRuntime) PullImage(ctx context.Context, params *PullParams, progress func(*PullStatus) error) (string, error) {
+	if !f.Available(ctx) {
+		return "", fmt.Errorf("%w: fake", errRuntimeUnavailable)
+	}
+
+	sum := sha256.Sum256([]byte(params.Image))
+	hash := "sha256:" + hex.EncodeToString(sum[:])
+	digest := "sha256:" + hex.EncodeToString(sum[16:]) + hex.EncodeToString(sum[:16])
+	layer := hex.EncodeToString(sum[:6])
+
+	for _, status := range []*PullStatus{
+		{Layer: layer, Status: "Pulling fs layer"},
+		{Layer: layer, Status: "Pull complete", Current: 1, Total: 1},
+		{Status: "Digest: " + digest},
+	} {
+		if err := progress(status); err != nil {
+			return "", err
+		}
+	}
+
+	// Дайджест в реестре указывается без тега: registry:5000/app:1.0 -> registry:5000/app@sha256:...
+	repo, _, _ := strings.Cut(params.Image, "@")
+
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+
+	img := &DockerImage{
+		Id:      hashId(hash),
+		Name:    params.Image,
+		Tags:    []string{params.Image},
+		Digests: []string{repo + "@" + digest},
+		Hash:    hash,
+	}
+	f.AddImage(img)
+
+	return digest, nil
+}
+
+// CreateContainer создает и запускает контейнер из образа, найденного по хешу или тегу.
+//
+// @param ctx контекст операции
+// @param spec указатель на проверенную ContainerSpec
+// @return указатель на DockerContainer и ошибка (если есть)
+func (f *FakeRuntime) CreateContainer(ctx context.Context, spec *ContainerSpec) (*DockerContainer, error) {
+	f.mu.Lock()
+
+	if err := f.check(); err != nil {
+		f.mu.Unlock()
+		return nil, err
+	}
+
+	img := f.findImage(spec.Image)
+
+	if img == nil {
+		f.mu.Unlock()
+		return nil, fmt.Errorf("no such image: %s", spec.Image)
+	}
+
+	raw := make([]byte, 32)
+	_, _ = rand.Read(raw)
+	hash := hex.EncodeToString(raw)
+
+	name := spec.Name
+
+	if name == "" {
+		name = hash[:12]
+	}
+
+	for _, ctr := range f.containers {
+		if ctr.Name == "/"+name {
+			f.mu.Unlock()
+			return nil, fmt.Errorf("container name %q is already in use", name)
+		}
+	}
+
+	f.mu.Unlock()
+
+	ctr := &DockerContainer{
+		Id:        hashId(hash),
+		Name:      "/" + name,
+		ImageId:   img.Id,
+		ImageHash: img.Hash,
+		Labels:    maps.Clone(spec.Labels),
+		Hash:      hash,
+	}
+
+	if spec.Resources != nil {
+		limits := *spec.Resources
+		ctr.Limits = &limits
+	}
+
+	if spec.RestartPolicy != nil {
+		policy := *spec.RestartPolicy
+		ctr.RestartPolicy = &policy
+	}
+
+	f.AddContainer(ctr)
+
+	if err := f.StartContainer(ctx, hash); err != nil {
+		return nil, err
+	}
+
+	return f.InspectContainer(ctx, hash)
+}
+
+// findImage ищет образ по хешу или тегу. Вызывается под мьютексом.
+//
+// @param ref хеш или тег образа
+// @return указатель на DockerImage или nil
+func (f *FakeRuntime) findImage(ref string) *DockerImage {
+	for hash, img := range f.images {
+		if hash == ref || slices.Contains(img.Tags, ref) {
+			return img
+		}
+	}
+
+	return nil
+}
+
+// Volumes возвращает копии всех томов. Как и в Docker, размер в списке неизвестен:
+// Size и RefCount равны -1, а заданные значения возвращают VolumeSizes и DiskUsage.
+//
+// @param ctx контекст операции
+// @return срез указателей на DockerVolume и ошибка (если есть)
+func (f *FakeRuntime) Volumes(ctx context.Context) ([]*DockerVolume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	vols := make([]*DockerVolume, 0, len(f.volumes))
+
+	for _, vol := range f.volumes {
+		copied := *vol
+		copied.Size, copied.RefCount = -1, -1
+		vols = append(vols, &copied)
+	}
+
+	return vols, nil
+}
+
+// VolumeSizes возвращает копии всех томов с заданными размерами.
+//
+// @param ctx контекст операции
+// @return срез указателей на DockerVolume и ошибка (если есть)
+func (f *FakeRuntime) VolumeSizes(ctx context.Context) ([]*DockerVolume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	vols := make([]*DockerVolume, 0, len(f.volumes))
+
+	for _, vol := range f.volumes {
+		copied := *vol
+		vols = append(vols, &copied)
+	}
+
+	return vols, nil
+}
+
+// Networks возвращает копии всех сетей.
+//
+// @param ctx контекст операции
+// @return срез указателей на DockerNetwork и ошибка (если есть)
+func (f *FakeRuntime) Networks(ctx context.Context) ([]*DockerNetwork, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	nets := make([]*DockerNetwork, 0, len(f.networks))
+
+	for _, net := range f.networks {
+		copied := *net
+		copied.Containers = slices.Clone(net.Containers)
+		nets = append(nets, &copied)
+	}
+
+	return nets, nil
+}
+
+// RemoveVolume удаляет том.
+//
+// @param ctx контекст операции
+// @param name имя тома
+// @return ошибка (если есть)
+func (f *FakeRuntime) RemoveVolume(ctx context.Context, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+
+	vol, ok := f.volumes[name]
+
+	if !ok {
+		return fmt.Errorf("no such volume: %s", name)
+	}
+
+	if vol.RefCount > 0 {
+		return fmt.Errorf("volume is in use: %s", name)
+	}
+
+	delete(f.volumes, name)
+	f.publish(RuntimeEvent{Type: EventVolume, Action: "destroy", Id: name})
+
+	return nil
+}
+
+// RemoveNetwork удаляет сеть.
+//
+// @param ctx контекст операции
+// @param networkHash хеш или имя сети
+// @return ошибка (если есть)
+func (f *FakeRuntime) RemoveNetwork(ctx context.Context, networkHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+
+	for hash, net := range f.networks {
+		if hash != networkHash && net.Name != networkHash {
+			continue
+		}
+
+		if len(net.Containers) > 0 {
+			return fmt.Errorf("network %s has active endpoints", networkHash)
+		}
+
+		delete(f.networks, hash)
+		f.publish(RuntimeEvent{Type: EventNetwork, Action: "destroy", Id: hash})
+
+		return nil
+	}
+
+	return fmt.Errorf("no such network: %s", networkHash)
+}
+
+// PruneVolumes удаляет тома, которые не использует ни один контейнер.
+// Фильтры по меткам и параметр All не учитываются.
+//
+// @param ctx контекст операции
+// @param params не используется
+// @return указатель на PruneReport и ошибка (если есть)
+func (f *FakeRuntime) PruneVolumes(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	report := &PruneReport{Deleted: []string{}}
+
+	for name, vol := range f.volumes {
+		if vol.RefCount > 0 {
+			continue
+		}
+
+		delete(f.volumes, name)
+		report.Deleted = append(report.Deleted, name)
+		report.SpaceReclaimed += uint64(max(vol.Size, 0))
+		f.publish(RuntimeEvent{Type: EventVolume, Action: "destroy", Id: name})
+	}
+
+	return report, nil
+}
+
+// PruneNetworks удаляет сети без подключённых контейнеров, кроме встроенных bridge, host и none.
+// Фильтры не учитываются.
+//
+// @param ctx контекст операции
+// @param params не используется
+// @return указатель на PruneReport и ошибка (если есть)
+func (f *FakeRuntime) PruneNetworks(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	report := &PruneReport{Deleted: []string{}}
+
+	for hash, net := range f.networks {
+		if len(net.Containers) > 0 || net.Name == "bridge" || net.Name == "host" || net.Name == "none" {
+			continue
+		}
+
+		delete(f.networks, hash)
+		report.Deleted = append(report.Deleted, net.Name)
+		f.publish(RuntimeEvent{Type: EventNetwork, Action: "destroy", Id: hash})
+	}
+
+	return report, nil
+}
+
+// DiskUsage возвращает отчёт об использовании диска образами и томами.
+// Контейнеры и кеш сборки места не занимают.
+//
+// @param ctx контекст операции
+// @return указатель на DiskUsageReport и ошибка (если есть)
+func (f *FakeRuntime) DiskUsage(ctx context.Context) (*DiskUsageReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	report := &DiskUsageReport{VolumeSizes: make([]*DockerVolume, 0, len(f.volumes))}
+
+	for hash, img := range f.images {
+		report.Images.Total++
+		report.Images.Size += int64(img.Size)
+
+		if f.imageUsed(hash) {
+			report.Images.Active++
+		} else {
+			report.Images.Reclaimable += int64(img.Size)
+		}
+	}
+
+	for _, ctr := range f.containers {
+		report.Containers.Total++
+
+		if ctr.Status == "running" {
+			report.Containers.Active++
+		}
+	}
+
+	for _, vol := range f.volumes {
+		copied := *vol
+		report.Volumes.Total++
+		report.VolumeSizes = append(report.VolumeSizes, &copied)
+		report.Volumes.Size += max(vol.Size, 0)
+
+		if vol.RefCount > 0 {
+			report.Volumes.Active++
+		} else {
+			report.Volumes.Reclaimable += max(vol.Size, 0)
+		}
+	}
+
+	return report, nil
+}
+
+// imageUsed сообщает, использует ли образ хотя бы один контейнер. Вызывается под мьютексом.
+//
+// @param imageHash хеш образа
+// @return true, если образ используется
+func (f *FakeRuntime) imageUsed(imageHash string) bool {
+	for _, ctr := range f.containers {
+		if ctr.ImageHash == imageHash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PruneContainers удаляет все незапущенные контейнеры. Фильтры не учитываются.
+//
+// @param ctx контекст операции
+// @param params не используется
+// @return указатель на PruneReport и ошибка (если есть)
+func (f *FakeRuntime) PruneContainers(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	report := &PruneReport{Deleted: []string{}}
+
+	for hash, ctr := range f.containers {
+		if ctr.Status == "running" || ctr.Status == "paused" || ctr.Status == "restarting" {
+			continue
+		}
+
+		delete(f.containers, hash)
+		report.Deleted = append(report.Deleted, hash)
+		f.publish(RuntimeEvent{Type: EventContainer, Action: "destroy", Id: hash, Attributes: containerAttributes(ctr)})
+	}
+
+	return report, nil
+}
+
+// PruneImages удаляет образы, не используемые контейнерами. Без All — только образы без тегов.
+// Фильтры не учитываются.
+//
+// @param ctx контекст операции
+// @param params параметры удаления
+// @return указатель на PruneReport и ошибка (если есть)
+func (f *FakeRuntime) PruneImages(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	report := &PruneReport{Deleted: []string{}}
+
+	for hash, img := range f.images {
+		if f.imageUsed(hash) || (!params.All && len(img.Tags) > 0) {
+			continue
+		}
+
+		delete(f.images, hash)
+		report.Deleted = append(report.Deleted, hash)
+		report.SpaceReclaimed += uint64(img.Size)
+		f.publish(RuntimeEvent{Type: EventImage, Action: "delete", Id: hash})
+	}
+
+	return report, nil
+}
+
+// PruneBuildCache ничего не удаляет: у среды выполнения в памяти нет кеша сборки.
+//
+// @param ctx контекст операции
+// @param params не используется
+// @return пустой PruneReport и ошибка (если есть)
+func (f *FakeRuntime) PruneBuildCache(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	return &PruneReport{Deleted: []string{}}, nil
+}
+
+// InspectDetails возвращает подробные сведения о контейнере, известные среде выполнения в памяти.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @return указатель на ContainerDetails и ошибка (если есть)
+func (f *FakeRuntime) InspectDetails(ctx context.Context, containerHash string) (*ContainerDetails, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	ctr := f.find(containerHash)
+
+	if ctr == nil {
+		return nil, fmt.Errorf("no such container: %s", containerHash)
+	}
+
+	details := &ContainerDetails{
+		Hash:          ctr.Hash,
+		Name:          ctr.Name,
+		Image:         ctr.ImageHash,
+		ImageHash:     ctr.ImageHash,
+		Status:        ctr.Status,
+		Health:        ctr.Health,
+		RestartCount:  ctr.RestartCount,
+		Labels:        maps.Clone(ctr.Labels),
+		RestartPolicy: *ctr.RestartPolicy,
+		Resources:     *ctr.Limits,
+		Ports:         []PortBinding{},
+		Mounts:        []ContainerMount{},
+		Networks:      []ContainerEndpoint{},
+	}
+
+	if img, ok := f.images[ctr.ImageHash]; ok && len(img.Tags) > 0 {
+		details.Image = img.Tags[0]
+	}
+
+	return details, nil
+}
+
+// CopyToContainer сохраняет обычные файлы из tar-архива в каталоге контейнера.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @param dir каталог назначения внутри контейнера
+// @param archive tar-архив
+// @return ошибка (если есть)
+func (f *FakeRuntime) CopyToContainer(ctx context.Context, containerHash string, dir string, archive io.Reader) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+
+	ctr := f.find(containerHash)
+
+	if ctr == nil {
+		return fmt.Errorf("no such container: %s", containerHash)
+	}
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(archive)
+
+	for {
+		header, err := tr.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+
+		if err != nil {
+			return err
+		}
+
+		files[path.Join(dir, header.Name)] = content
+	}
+
+	if f.files[ctr.Hash] == nil {
+		f.files[ctr.Hash] = make(map[string][]byte)
+	}
+
+	maps.Copy(f.files[ctr.Hash], files)
+
+	return nil
+}
+
+// CopyFromContainer возвращает tar-архив файла или каталога, сохранённого CopyToContainer.
+//
+// @param ctx контекст операции
+// @param containerHash хеш контейнера
+// @param srcPath путь внутри контейнера
+// @return io.ReadCloser с tar-архивом и ошибка (если есть)
+func (f *FakeRuntime) CopyFromContainer(ctx context.Context, containerHash string, srcPath string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	ctr := f.find(containerHash)
+
+	if ctr == nil {
+		return nil, fmt.Errorf("no such container: %s", containerHash)
+	}
+
+	src := path.Clean(srcPath)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	found := false
+
+	for _, name := range slices.Sorted(maps.Keys(f.files[ctr.Hash])) {
+		if name != src && !strings.HasPrefix(name, strings.TrimSuffix(src, "/")+"/") {
+			continue
+		}
+
+		content := f.files[ctr.Hash][name]
+		found = true
+
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Base(src) + strings.TrimPrefix(name, src),
+			Size:     int64(len(content)),
+			Mode:     0644,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tw.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no such file or directory: %s", srcPath)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(&buf), nil
+}
+
+// UpdateContainer изменяет ограничения ресурсов и политику перезапуска контейнера
+// и отправляет подписчикам событие update. Нулевые ограничения не меняются.
+//
+// @param ctx контекст операции
+// @param params указатель на проверенные UpdateParams
+// @return указатель на UpdateReport с действующими настройками и ошибка (если есть)
+func (f *FakeRuntime) UpdateContainer(ctx context.Context, params *UpdateParams) (*UpdateReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	ctr := f.find(params.Hash)
+
+	if ctr == nil {
+		return nil, fmt.Errorf("no such container: %s", params.Hash)
+	}
+
+	// Настройки заменяются новыми значениями, а не меняются на месте:
+	// копии контейнера, выданные InspectContainer, ссылаются на прежние
+	limits := *ctr.Limits
+	policy := *ctr.RestartPolicy
+
+	if res := params.Resources; res != nil {
+		if res.CpuShares != 0 {
+			limits.CpuShares = res.CpuShares
+		}
+
+		if res.CpuPeriod != 0 {
+			limits.CpuPeriod = res.CpuPeriod
+		}
+
+		if res.CpuQuota != 0 {
+			limits.CpuQuota = res.CpuQuota
+		}
+
+		if res.Memory != 0 {
+			limits.Memory = res.Memory
+		}
+
+		if res.MemoryReservation != 0 {
+			limits.MemoryReservation = res.MemoryReservation
+		}
+	}
+
+	if params.RestartPolicy != nil {
+		policy = *params.RestartPolicy
+	}
+
+	ctr.Limits = &limits
+	ctr.RestartPolicy = &policy
+	f.publish(RuntimeEvent{Type: EventContainer, Action: "update", Id: ctr.Hash})
+
+	return &UpdateReport{Resources: limits, RestartPolicy: policy, Warnings: []string{}}, nil
+}
+
+// Events подписывается на события до отмены ctx.
+//
+// @param ctx контекст подписки
+// @return канал событий и канал ошибок
+func (f *FakeRuntime) Events(ctx context.Context) (<-chan RuntimeEvent, <-chan error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	errs := make(chan error, 1)
+
+	if err := f.check(); err != nil {
+		errs <- err
+		return nil, errs
+	}
+
+	events := make(chan RuntimeEvent, 64)
+	f.subscribers = append(f.subscribers, events)
+
+	go func() {
+		<-ctx.Done()
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		for i, sub := range f.subscribers {
+			if sub == events {
+				f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+				break
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+// publish отправляет событие всем подписчикам, не блокируясь. Вызывается под мьютексом.
+//
+// @param event событие среды выполнения
+func (f *FakeRuntime) publish(event RuntimeEvent) {
+	for _, sub := range f.subscribers {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
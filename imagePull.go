@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"io"
+	"strings"
+	"time"
+)
+
+// pullProgressInterval задаёт минимальный интервал между сообщениями о загрузке одного слоя.
+const pullProgressInterval = 500 * time.Millisecond
+
+// pullMessage описывает одно сообщение потока загрузки образа из Docker API.
+type pullMessage struct {
+	Status         string // Состояние загрузки.
+	Id             string // Идентификатор слоя.
+	ProgressDetail struct {
+		Current int64 // Загружено байт.
+		Total   int64 // Размер слоя в байтах.
+	}
+	Error string // Текст ошибки загрузки.
+}
+
+// handlePullImage обрабатывает команду загрузки образа из реестра.
+// Ход загрузки передаётся сообщениями PullProgress, а в Output результата — дайджест загруженного образа.
+// Событие AddedDockerImage отправляет только DockerWatcher по событию pull, если образ новый,
+// поэтому сервер получает его ровно один раз. Если образ уже был загружен или загрузка лишь
+// добавила тег существующему образу, AddedDockerImage не отправляется.
+//
+// @param ctx контекст операции
+// @param c указатель на Communicator
+// @param message входящее сообщение, Data содержит PullParams в JSON
+// @return указатель на CommandResult
+func handlePullImage(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+	var params PullParams
+
+	if err := json.Unmarshal([]byte(message.Data), &params); err != nil {
+		return errorResult(fmt.Errorf("ошибка разбора параметров команды: %v", err))
+	}
+
+	if params.Image == "" {
+		return errorResult(fmt.Errorf("не указан образ"))
+	}
+
+	digest, err := c.Runtime.PullImage(ctx, &params, func(status *PullStatus) error {
+		data, _ := json.Marshal(status)
+		return c.sendContext(ctx, &SentMessage{Type: PullProgress, Id: message.Id, Data: string(data)})
+	})
+
+	if err != nil {
+		return errorResult(err)
+	}
+
+	return &CommandResult{Success: true, Output: digest}
+}
+
+// PullImage загружает образ из реестра и возвращает его дайджест.
+// Промежуточные сообщения о загрузке слоя прореживаются до одного в pullProgressInterval.
+//
+// @param ctx контекст операции
+// @param params параметры загрузки
+// @param progress вызывается для каждого сообщения о ходе загрузки; ошибка прерывает загрузку
+// @return дайджест образа и ошибка (если есть)
+func (m *DockerRuntime) PullImage(ctx context.Context, params *PullParams, progress func(*PullStatus) error) (string, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	opts := image.PullOptions{Platform: params.Platform}
+
+	if params.Auth != nil {
+		auth, err := registry.EncodeAuthConfig(registry.AuthConfig{
+			Username:      params.Auth.Username,
+			Password:      params.Auth.Password,
+			IdentityToken: params.Auth.IdentityToken,
+			ServerAddress: params.Auth.ServerAddress,
+		})
+
+		if err != nil {
+			return "", fmt.Errorf("ошибка кодирования учётных данных реестра: %v", err)
+		}
+
+		opts.RegistryAuth = auth
+	}
+
+	reader, err := cli.ImagePull(ctx, params.Image, opts)
+
+	if err != nil {
+		return "", fmt.Errorf("ошибка загрузки образа: %v", err)
+	}
+
+	defer reader.Close()
+
+	digest := ""
+	decoder := json.NewDecoder(reader)
+	sentAt := make(map[string]time.Time)
+
+	for {
+		var msg pullMessage
+
+		if err := decoder.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+
+			return "", fmt.Errorf("ошибка чтения хода загрузки образа: %v", err)
+		}
+
+		if msg.Error != "" {
+			return "", fmt.Errorf("ошибка загрузки образа: %s", msg.Error)
+		}
+
+		if value, ok := strings.CutPrefix(msg.Status, "Digest: "); ok {
+			digest = value
+		}
+
+		detail := msg.ProgressDetail
+		inProgress := detail.Total > 0 && detail.Current < detail.Total
+
+		if inProgress && time.Since(sentAt[msg.Id]) < pullProgressInterval {
+			continue
+		}
+
+		sentAt[msg.Id] = time.Now()
+
+		if err := progress(&PullStatus{Layer: msg.Id, Status: msg.Status, Current: detail.Current, Total: detail.Total}); err != nil {
+			return "", err
+		}
+	}
+
+	if digest != "" {
+		return digest, nil
+	}
+
+	// Если реестр не сообщил дайджест (образ уже был загружен), берём его из описания образа
+	info, err := cli.ImageInspect(ctx, params.Image)
+
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения загруженного образа: %v", err)
+	}
+
+	if len(info.RepoDigests) > 0 {
+		if _, value, ok := strings.Cut(info.RepoDigests[0], "@"); ok {
+			digest = value
+		}
+	}
+
+	return digest, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// pullStream возвращает обработчик, отвечающий сообщениями потока загрузки образа.
+func pullStream(messages ...any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+
+		for _, msg := range messages {
+			_ = encoder.Encode(msg)
+		}
+	}
+}
+
+func TestDockerPullImage(t *testing.T) {
+	const digest = "sha256:4444444444444444444444444444444444444444444444444444444444444444"
+	var query string
+	var auth registry.AuthConfig
+
+	stream := pullStream(
+		map[string]any{"status": "Pulling from app", "id": "1.0"},
+		map[string]any{"status": "Pulling fs layer", "id": "a1"},
+		map[string]any{"status": "Downloading", "id": "a1", "progressDetail": map[string]int64{"current": 10, "total": 100}},
+		map[string]any{"status": "Downloading", "id": "a1", "progressDetail": map[string]int64{"current": 50, "total": 100}},
+		map[string]any{"status": "Downloading", "id": "a1", "progressDetail": map[string]int64{"current": 90, "total": 100}},
+		map[string]any{"status": "Pull complete", "id": "a1"},
+		map[string]any{"status": "Digest: " + digest},
+	)
+
+	rt := newFakeDockerApi(t, map[string]http.HandlerFunc{
+		"POST /images/create": func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.RawQuery
+			decoded, err := registry.DecodeAuthConfig(r.Header.Get(registry.AuthHeader))
+
+			if err == nil {
+				auth = *decoded
+			}
+
+			stream(w, r)
+		},
+	})
+
+	var statuses []*PullStatus
+
+	got, err := rt.PullImage(context.Background(), &PullParams{
+		Image: "registry:5000/app:1.0",
+		Auth:  &RegistryAuth{Username: "ci", Password: "secret", ServerAddress: "registry:5000"},
+	}, func(status *PullStatus) error {
+		statuses = append(statuses, status)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query != "fromImage=registry%3A5000%2Fapp&tag=1.0" {
+		t.Errorf("неверные параметры загрузки: %q", query)
+	}
+
+	if auth.Username != "ci" || auth.Password != "secret" || auth.ServerAddress != "registry:5000" {
+		t.Errorf("учётные данные реестра не переданы: %+v", auth)
+	}
+
+	if got != digest {
+		t.Errorf("получен дайджест %q, ожидался %q", got, digest)
+	}
+
+	// Сообщения Downloading одного слоя приходят чаще pullProgressInterval и прореживаются,
+	// а остальные сообщения передаются всегда
+	var reported []string
+	downloading := 0
+
+	for _, status := range statuses {
+		if status.Status == "Downloading" {
+			downloading++
+			continue
+		}
+
+		reported = append(reported, status.Status)
+	}
+
+	want := []string{"Pulling from app", "Pulling fs layer", "Pull complete", "Digest: " + digest}
+
+	if downloading > 1 || !slices.Equal(reported, want) {
+		t.Errorf("получены сообщения %q и %d Downloading, ожидались %q и не более одного Downloading", reported, downloading, want)
+	}
+}
+
+func TestDockerPullImageDigestFromInspect(t *testing.T) {
+	rt := newFakeDockerApi(t, map[string]http.HandlerFunc{
+		"POST /images/create": pullStream(map[string]any{"status": "Status: Image is up to date for app:latest"}),
+		"GET /images/app:latest/json": writeJson(image.InspectResponse{
+			ID:          imageHash,
+			RepoTags:    []string{"app:latest"},
+			RepoDigests: []string{"app@sha256:5555"},
+		}),
+	})
+
+	digest, err := rt.PullImage(context.Background(), &PullParams{Image: "app:latest"}, func(*PullStatus) error { return nil })
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if digest != "sha256:5555" {
+		t.Errorf("дайджест должен браться из RepoDigests, получен %q", digest)
+	}
+}
+
+func TestDockerPullImageError(t *testing.T) {
+	rt := newFakeDockerApi(t, map[string]http.HandlerFunc{
+		"POST /images/create": pullStream(
+			map[string]any{"status": "Pulling from app"},
+			map[string]any{"error": "pull access denied for app"},
+		),
+	})
+
+	_, err := rt.PullImage(context.Background(), &PullParams{Image: "app:latest"}, func(*PullStatus) error { return nil })
+
+	if err == nil {
+		t.Fatal("ошибка из потока загрузки должна прерывать загрузку")
+	}
+}
+
+func TestHandlePullImageEmitsAddedOnce(t *testing.T) {
+	h := newWatcherHarness(t, NewFakeRuntime())
+
+	data, _ := json.Marshal(&PullParams{Image: "registry:5000/app:1.0"})
+	result := handlePullImage(context.Background(), h.com, &ReceiveMessage{Id: "pull-1", Data: string(data)})
+
+	if !result.Success || result.Output == "" {
+		t.Fatalf("загрузка завершилась ошибкой или без дайджеста: %+v", result)
+	}
+
+	progress := 0
+
+	for len(h.com.outgoing) > 0 {
+		message := <-h.com.outgoing
+
+		if message.Type != PullProgress || message.Id != "pull-1" {
+			t.Fatalf("неожиданное сообщение: %+v", message)
+		}
+
+		progress++
+	}
+
+	if progress == 0 {
+		t.Error("не получено ни одного сообщения о ходе загрузки")
+	}
+
+	expectSent(t, h.process(), AddedDockerImage)
+
+	// Повторная загрузка того же образа не добавляет новый образ
+	handlePullImage(context.Background(), h.com, &ReceiveMessage{Id: "pull-2", Data: string(data)})
+	expectSent(t, h.process())
+}
@@ -19,6 +19,7 @@ func ensureConfig(path string) (*Config, error) {
 			Ip:    "127.0.0.1:8080",
 			Token: "your_token_here",
 		}
+		defaultCfg.setDefaults()
 
 		data, err := json.MarshalIndent(defaultCfg, "", "	")
 
@@ -57,89 +58,43 @@ func loadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	cfg.setDefaults()
+
 	return &cfg, nil
 }
 
-// watchDocker отслеживает изменения docker-контейнеров и образов с заданным интервалом.
-// При обнаружении изменений отправляет соответствующие сообщения через Communicator.
-//
-// @param c указатель на Communicator
-// @param interval интервал проверки изменений
-func watchDocker(c *Communicator, interval time.Duration) {
-	prevCont := GetAllDockerContainers()
-	prevImg := GetAllDockerImages()
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	prevCMap := make(map[string]*DockerContainer)
-	for _, ctr := range prevCont {
-		prevCMap[ctr.Hash] = ctr
+// setDefaults заполняет незаданные параметры конфигурации значениями по умолчанию.
+func (cfg *Config) setDefaults() {
+	if cfg.ReconnectMinDelay <= 0 {
+		cfg.ReconnectMinDelay = 1
 	}
 
-	prevImgMap := make(map[string]*DockerImage)
-	for _, img := range prevImg {
-		prevImgMap[img.Hash] = img
+	if cfg.ReconnectMaxDelay <= 0 {
+		cfg.ReconnectMaxDelay = 60
 	}
 
-	for range ticker.C {
-		// Docker containers
-		currCont := GetAllDockerContainers()
-
-		currCMap := make(map[string]*DockerContainer)
-		for _, ctr := range currCont {
-			currCMap[ctr.Hash] = ctr
-		}
-
-		// Check for added, updated containers
-		for id, ctr := range currCMap {
-			if _, ok := prevCMap[id]; !ok {
-				data, _ := json.Marshal(ctr)
-				c.Requests.Add(&SentMessage{Type: AddedDockerContainer, Data: string(data)})
-			}
-
-			if prev, ok := prevCMap[id]; ok && (prev.Status != ctr.Status || prev.Recourses != ctr.Recourses) {
-				data, _ := json.Marshal(ctr)
-				c.Requests.Add(&SentMessage{Type: UpdatedDockerContainer, Data: string(data)})
-			}
-		}
-
-		// Check for removed containers
-		for id, ctr := range prevCMap {
-			if _, ok := currCMap[id]; !ok {
-				data, _ := json.Marshal(ctr)
-				c.Requests.Add(&SentMessage{Type: RemovedDockerContainer, Data: string(data)})
-			}
-		}
-
-		prevCMap = currCMap
-		prevCont = currCont
+	if cfg.MetricInterval <= 0 {
+		cfg.MetricInterval = 10
+	}
 
-		// Docker images
-		currImg := GetAllDockerImages()
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 15
+	}
 
-		currImgMap := make(map[string]*DockerImage)
-		for _, img := range currImg {
-			currImgMap[img.Hash] = img
-		}
+	if cfg.PongTimeout <= cfg.PingInterval {
+		cfg.PongTimeout = 3 * cfg.PingInterval
+	}
 
-		// Check for added images
-		for id, img := range currImgMap {
-			if _, ok := prevImgMap[id]; !ok {
-				data, _ := json.Marshal(img)
-				c.Requests.Add(&SentMessage{Type: AddedDockerImage, Data: string(data)})
-			}
-		}
+	if cfg.DockerResyncInterval <= 0 {
+		cfg.DockerResyncInterval = 60
+	}
 
-		// Check for removed images
-		for id, img := range prevImgMap {
-			if _, ok := currImgMap[id]; !ok {
-				data, _ := json.Marshal(img)
-				c.Requests.Add(&SentMessage{Type: RemovedDockerImage, Data: string(data)})
-			}
-		}
+	if cfg.SpoolDir == "" {
+		cfg.SpoolDir = "spool"
+	}
 
-		prevImgMap = currImgMap
-		prevImg = currImg
+	if cfg.SpoolMaxSize <= 0 {
+		cfg.SpoolMaxSize = 64
 	}
 }
 
@@ -152,12 +107,23 @@ func main() {
 		os.Exit(0)
 	}
 
-	com := NewCommunicator(cfg.Token, cfg.Ip)
+	rt, err := NewContainerRuntime(cfg)
+
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+
+	com, err := NewCommunicator(cfg, rt)
+
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
 
-	com.Connect()
 	com.StartHandlingThread()
 
-	go watchDocker(com, 10*time.Second)
+	go NewDockerWatcher(com, rt, time.Duration(cfg.DockerResyncInterval)*time.Second).Run()
 
 	select {} // Держим приложение живым
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// operations хранит выполняющиеся команды по идентификатору запроса,
+// чтобы сервер мог отменить долгую операцию (например, чтение логов с follow).
+type operations struct {
+	mu      sync.Mutex                    // Мьютекс для синхронизации доступа к cancels.
+	cancels map[string]context.CancelFunc // Функции отмены по идентификатору запроса.
+}
+
+// start регистрирует операцию и возвращает её контекст.
+// Контекст производный от контекста сессии, поэтому операция отменяется и при разрыве соединения.
+// Операции без идентификатора не регистрируются и не могут быть отменены командой CancelOperation.
+//
+// @param session контекст сессии, в которой получена команда
+// @param id идентификатор запроса
+// @return контекст операции и функция, которую нужно вызвать по её завершении
+func (o *operations) start(session context.Context, id string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(session)
+
+	if id == "" {
+		return ctx, cancel
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.cancels == nil {
+		o.cancels = make(map[string]context.CancelFunc)
+	}
+
+	o.cancels[id] = cancel
+
+	return ctx, func() {
+		cancel()
+
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		delete(o.cancels, id)
+	}
+}
+
+// cancel отменяет операцию по идентификатору запроса.
+//
+// @param id идентификатор запроса
+// @return false, если операция не найдена
+func (o *operations) cancel(id string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	cancel, ok := o.cancels[id]
+
+	if ok {
+		cancel()
+	}
+
+	return ok
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// waitOperations ждёт, пока все операции Communicator завершатся.
+func waitOperations(t *testing.T, c *Communicator) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		c.operations.mu.Lock()
+		running := len(c.operations.cancels)
+		c.operations.mu.Unlock()
+
+		if running == 0 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("операции не завершились")
+}
+
+func TestSessionEndCancelsOperations(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.AddContainer(newTestContainer(runningHash, "running"))
+	c := newTestCommunicator(t, rt)
+	session, cancel := context.WithCancel(context.Background())
+
+	data, _ := json.Marshal(&LogsParams{Hash: runningHash, Follow: true})
+	c.dispatchCommand(session, &ReceiveMessage{Type: ContainerLogs, Id: "logs-1", Data: string(data)})
+	c.dispatchCommand(session, &ReceiveMessage{Type: ExecContainer, Id: "exec-1", Data: `{"Hash":"` + runningHash + `","Cmd":["sh"],"Tty":true}`})
+	c.dispatchCommand(session, &ReceiveMessage{Type: CopyToContainer, Id: "copy-1", Data: `{"Hash":"` + runningHash + `","Path":"/tmp/a","Size":1,"Sha256":"00"}`})
+
+	cancel()
+	c.terminals.closeAll()
+	c.uploads.closeAll()
+	waitOperations(t, c)
+
+	// Результаты прерванных команд не должны попасть в следующую сессию
+	for len(c.outgoing) > 0 {
+		if message := <-c.outgoing; message.Type == Result {
+			t.Errorf("отправлен результат прерванной команды: %+v", message)
+		}
+	}
+
+	if c.terminals.get("exec-1") != nil || c.uploads.get("copy-1") != nil {
+		t.Error("интерактивная сессия или загрузка осталась зарегистрированной")
+	}
+}
+
+func TestCancelOperation(t *testing.T) {
+	var o operations
+	ctx, done := o.start(context.Background(), "op-1")
+	defer done()
+
+	if !o.cancel("op-1") || ctx.Err() == nil {
+		t.Fatal("операция не отменена")
+	}
+
+	if o.cancel("op-2") {
+		t.Fatal("отменена незарегистрированная операция")
+	}
+}
+
+func TestCancelRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("команда sleep недоступна в Windows")
+	}
+
+	c := newTestCommunicator(t, NewFakeRuntime())
+	marker := filepath.Join(t.TempDir(), "started")
+
+	// Команда из нескольких частей не заменяется процессом sleep, поэтому sleep остаётся дочерним процессом sh
+	c.dispatchCommand(context.Background(), &ReceiveMessage{Type: RunCommand, Id: "cmd-1", Data: "touch " + marker + "; sleep 30; true"})
+
+	// Отмена отправляется, когда команда уже выполняется, а не до запуска процесса
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(marker); err == nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("команда не запустилась")
+		}
+	}
+
+	started := time.Now()
+	c.dispatchCommand(context.Background(), &ReceiveMessage{Type: CancelOperation, Id: "cancel-1", Data: "cmd-1"})
+	waitOperations(t, c)
+
+	// Завершение по истечении processWaitDelay означает, что дочерний процесс пережил отмену
+	if elapsed := time.Since(started); elapsed >= processWaitDelay/2 {
+		t.Fatalf("команда завершилась через %v после отмены", elapsed)
+	}
+
+	for len(c.outgoing) > 0 {
+		if message := <-c.outgoing; message.Id == "cmd-1" {
+			if message.Data != canceledResult().Error {
+				t.Fatalf("неверный результат отменённой команды: %+v", message)
+			}
+
+			return
+		}
+	}
+
+	t.Fatal("не отправлен результат отменённой команды")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// outputChunkSize задаёт максимальный размер текста в одном сообщении OutputChunk.
+const outputChunkSize = 16 * 1024
+
+// outputStream передаёт потоковый вывод команды серверу сообщениями с OutputChunk.
+// Сообщения ставятся в очередь отправки с блокировкой, поэтому при медленном
+// соединении чтение источника приостанавливается, а не накапливается в памяти.
+type outputStream struct {
+	ctx         context.Context // Контекст операции.
+	c           *Communicator   // Communicator для отправки фрагментов.
+	id          string          // Идентификатор исходного запроса.
+	messageType TypeSentMessage // Тип сообщений с фрагментами.
+	seq         int64           // Номер следующего фрагмента.
+	writers     []*outputWriter // Потоки вывода запроса.
+}
+
+// outputWriter записывает один поток вывода (stdout или stderr) в outputStream.
+type outputWriter struct {
+	stream  *outputStream // Общий поток фрагментов запроса.
+	name    string        // Название потока вывода.
+	pending []byte        // Неполный UTF-8 символ в конце прошлой записи.
+}
+
+// newOutputStream создает поток фрагментов для ответа на запрос.
+//
+// @param ctx контекст операции; его отмена прерывает отправку
+// @param c указатель на Communicator
+// @param id идентификатор исходного запроса
+// @param messageType тип сообщений с фрагментами
+// @return указатель на outputStream
+func newOutputStream(ctx context.Context, c *Communicator, id string, messageType TypeSentMessage) *outputStream {
+	return &outputStream{ctx: ctx, c: c, id: id, messageType: messageType}
+}
+
+// writer возвращает io.Writer для потока вывода с указанным названием.
+// Оба потока демультиплексируются в одной горутине, поэтому seq не требует синхронизации.
+//
+// @param name название потока вывода (stdout или stderr)
+// @return указатель на outputWriter
+func (s *outputStream) writer(name string) *outputWriter {
+	w := &outputWriter{stream: s, name: name}
+	s.writers = append(s.writers, w)
+
+	return w
+}
+
+// flush отправляет отложенные неполные символы всех потоков вывода.
+// Вызывается после завершения источника; ошибки отправки не важны, так как
+// результат команды сообщит о причине завершения.
+func (s *outputStream) flush() {
+	for _, w := range s.writers {
+		_ = w.send(w.pending)
+		w.pending = nil
+	}
+}
+
+// Write отправляет данные серверу фрагментами не длиннее outputChunkSize.
+// Границы фрагментов не разрывают UTF-8 символы: неполный символ в конце записи
+// откладывается до следующей, иначе при кодировании в JSON он превратился бы в U+FFFD.
+//
+// @param p данные вывода
+// @return len(p) и ошибка, если операция отменена или соединение закрыто
+func (w *outputWriter) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	keep := incompleteRuneSuffix(data)
+	w.pending = append([]byte(nil), data[len(data)-keep:]...)
+
+	if err := w.send(data[:len(data)-keep]); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// send отправляет данные фрагментами, разбивая их только на границах символов.
+//
+// @param p данные без неполного символа в конце
+// @return ошибка, если операция отменена или соединение закрыто
+func (w *outputWriter) send(p []byte) error {
+	s := w.stream
+	written := 0
+
+	for written < len(p) {
+		end := min(written+outputChunkSize, len(p))
+
+		for end < len(p) && end > written && !utf8.RuneStart(p[end]) {
+			end--
+		}
+
+		// Данные не в UTF-8 разбиваются по размеру
+		if end == written {
+			end = min(written+outputChunkSize, len(p))
+		}
+
+		data, _ := json.Marshal(&OutputChunk{
+			Stream: w.name,
+			Seq:    s.seq,
+			Data:   string(p[written:end]),
+		})
+
+		if err := s.c.sendContext(s.ctx, &SentMessage{Type: s.messageType, Id: s.id, Data: string(data)}); err != nil {
+			return err
+		}
+
+		s.seq++
+		written = end
+	}
+
+	return nil
+}
+
+// incompleteRuneSuffix возвращает длину неполного UTF-8 символа в конце данных.
+//
+// @param p данные
+// @return число байт неполного символа (0, если данные заканчиваются целым символом)
+func incompleteRuneSuffix(p []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(p); i++ {
+		if !utf8.RuneStart(p[len(p)-i]) {
+			continue
+		}
+
+		if utf8.FullRune(p[len(p)-i:]) {
+			return 0
+		}
+
+		return i
+	}
+
+	return 0
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/docker/docker/client"
+)
+
+// NewPodmanRuntime создает среду выполнения Podman, работающую через его
+// Docker-совместимый API. Если сокет не указан, он определяется автоматически.
+//
+// @param socket путь к сокету Podman (пустая строка — автоопределение)
+// @return указатель на DockerRuntime
+func NewPodmanRuntime(socket string) *DockerRuntime {
+	if socket == "" {
+		socket = findPodmanSocket()
+	}
+
+	return newApiRuntime("podman", client.WithHost(socketHost(socket)))
+}
+
+// findPodmanSocket ищет сокет Podman в стандартных местах: переменная окружения
+// CONTAINER_HOST, пользовательский сокет (rootless) и системный сокет (rootful).
+//
+// @return адрес сокета; если ни один не найден — системный сокет по умолчанию
+func findPodmanSocket() string {
+	if host := os.Getenv("CONTAINER_HOST"); strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "tcp://") {
+		return host
+	}
+
+	if runtime.GOOS == "windows" {
+		return "npipe:////./pipe/podman-machine-default"
+	}
+
+	var candidates []string
+
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "podman", "podman.sock"))
+	}
+
+	candidates = append(candidates,
+		fmt.Sprintf("/run/user/%d/podman/podman.sock", os.Getuid()),
+		"/run/podman/podman.sock",
+		"/var/run/podman/podman.sock",
+	)
+
+	if home, err := os.UserHomeDir(); err == nil && runtime.GOOS == "darwin" {
+		candidates = append(candidates, filepath.Join(home, ".local", "share", "containers", "podman", "machine", "podman.sock"))
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode()&os.ModeSocket != 0 {
+			return candidate
+		}
+	}
+
+	return "/run/podman/podman.sock"
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import "os/exec"
+
+// setProcessGroup в Windows ничего не меняет: при отмене завершается сам процесс,
+// а ожидание вывода порождённых им процессов ограничено processWaitDelay.
+//
+// @param cmd процесс, ещё не запущенный
+func setProcessGroup(cmd *exec.Cmd) {}
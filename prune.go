@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"strconv"
+	"strings"
+)
+
+// parsePruneParams разбирает параметры команды удаления неиспользуемых объектов.
+// Пустые данные означают параметры по умолчанию.
+//
+// @param data параметры в JSON
+// @return указатель на PruneParams и ошибка (если есть)
+func parsePruneParams(data string) (*PruneParams, error) {
+	var params PruneParams
+
+	if strings.TrimSpace(data) == "" {
+		return &params, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), &params); err != nil {
+		return nil, fmt.Errorf("ошибка разбора параметров команды: %v", err)
+	}
+
+	return &params, nil
+}
+
+// pruneFilters преобразует параметры удаления в фильтры Docker API.
+//
+// @param params указатель на PruneParams
+// @return фильтры Docker API
+func pruneFilters(params *PruneParams) filters.Args {
+	args := filters.NewArgs()
+
+	if params.Until != "" {
+		args.Add("until", params.Until)
+	}
+
+	for _, label := range params.Labels {
+		if excluded, ok := strings.CutPrefix(label, "!"); ok {
+			args.Add("label!", excluded)
+		} else {
+			args.Add("label", label)
+		}
+	}
+
+	return args
+}
+
+// pruneResult преобразует результат удаления неиспользуемых объектов в CommandResult.
+// Отчёт передаётся в Output в JSON.
+//
+// @param report указатель на PruneReport
+// @param err ошибка операции (если есть)
+// @return указатель на CommandResult
+func pruneResult(report *PruneReport, err error) *CommandResult {
+	if err != nil {
+		return errorResult(err)
+	}
+
+	data, _ := json.Marshal(report)
+	return &CommandResult{Success: true, Output: string(data)}
+}
+
+// pruneCommand создает обработчик команды удаления неиспользуемых объектов.
+//
+// @param prune операция удаления с разобранными параметрами
+// @return commandHandler
+func pruneCommand(prune func(rt ContainerRuntime, ctx context.Context, params *PruneParams) (*PruneReport, error)) commandHandler {
+	return func(ctx context.Context, c *Communicator, message *ReceiveMessage) *CommandResult {
+		params, err := parsePruneParams(message.Data)
+
+		if err != nil {
+			return errorResult(err)
+		}
+
+		return pruneResult(prune(c.Runtime, ctx, params))
+	}
+}
+
+// handlePruneContainers обрабатывает команду удаления остановленных контейнеров.
+var handlePruneContainers = pruneCommand(ContainerRuntime.PruneContainers)
+
+// handlePruneImages обрабатывает команду удаления неиспользуемых образов.
+var handlePruneImages = pruneCommand(ContainerRuntime.PruneImages)
+
+// handlePruneBuildCache обрабатывает команду удаления кеша сборки.
+var handlePruneBuildCache = pruneCommand(func(rt ContainerRuntime, ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	if len(params.Labels) > 0 {
+		return nil, fmt.Errorf("фильтры по меткам не поддерживаются для кеша сборки")
+	}
+
+	return rt.PruneBuildCache(ctx, params)
+})
+
+// PruneContainers удаляет остановленные контейнеры.
+//
+// @param ctx контекст операции
+// @param params параметры удаления
+// @return указатель на PruneReport и ошибка (если есть)
+func (m *DockerRuntime) PruneContainers(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := cli.ContainersPrune(ctx, pruneFilters(params))
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка удаления остановленных контейнеров: %v", err)
+	}
+
+	return &PruneReport{Deleted: report.ContainersDeleted, SpaceReclaimed: report.SpaceReclaimed}, nil
+}
+
+// PruneImages удаляет неиспользуемые образы. Без All удаляются только образы без тегов (dangling).
+//
+// @param ctx контекст операции
+// @param params параметры удаления
+// @return указатель на PruneReport и ошибка (если есть)
+func (m *DockerRuntime) PruneImages(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	args := pruneFilters(params)
+	args.Add("dangling", strconv.FormatBool(!params.All))
+
+	report, err := cli.ImagesPrune(ctx, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка удаления неиспользуемых образов: %v", err)
+	}
+
+	result := &PruneReport{Deleted: []string{}, SpaceReclaimed: report.SpaceReclaimed}
+
+	for _, deleted := range report.ImagesDeleted {
+		if deleted.Deleted != "" {
+			result.Deleted = append(result.Deleted, deleted.Deleted)
+		}
+	}
+
+	return result, nil
+}
+
+// PruneBuildCache удаляет кеш сборки. Без All сохраняются записи, используемые образами.
+//
+// @param ctx контекст операции
+// @param params параметры удаления (фильтры по меткам не поддерживаются)
+// @return указатель на PruneReport и ошибка (если есть)
+func (m *DockerRuntime) PruneBuildCache(ctx context.Context, params *PruneParams) (*PruneReport, error) {
+	cli, err := m.client(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := cli.BuildCachePrune(ctx, types.BuildCachePruneOptions{All: params.All, Filters: pruneFilters(params)})
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка удаления кеша сборки: %v", err)
+	}
+
+	return &PruneReport{Deleted: report.CachesDeleted, SpaceReclaimed: report.SpaceReclaimed}, nil
+}
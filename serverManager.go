@@ -67,7 +67,7 @@ func getNetworkUsage() (int, int) {
 		return -1, -1
 	}
 
-	if len(counters) == 0 || len(counters) == 0 {
+	if len(counters) == 0 {
 		return -1, -1
 	}
 
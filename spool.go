@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// spoolSegmentExt задаёт расширение файлов сегментов спула.
+const spoolSegmentExt = ".seg"
+
+// spoolCursorFile задаёт имя файла с позицией чтения спула.
+const spoolCursorFile = "cursor"
+
+// spoolSegment описывает один файл сегмента спула.
+type spoolSegment struct {
+	id   int64 // Порядковый номер сегмента.
+	size int64 // Размер сегмента в байтах.
+}
+
+// SpoolPosition задаёт позицию записи в спуле: номер сегмента и смещение в нём.
+type SpoolPosition struct {
+	Segment int64 // Номер сегмента.
+	Offset  int64 // Смещение записи в сегменте.
+}
+
+// Before сообщает, находится ли позиция раньше other.
+//
+// @param other позиция для сравнения
+// @return true, если запись в этой позиции добавлена раньше
+func (p SpoolPosition) Before(other SpoolPosition) bool {
+	if p.Segment != other.Segment {
+		return p.Segment < other.Segment
+	}
+
+	return p.Offset < other.Offset
+}
+
+// Spool представляет персистентную очередь исходящих событий на диске.
+// События дописываются в сегментные файлы (по одному JSON на строку) и читаются
+// в порядке добавления. Позиция чтения сохраняется в отдельном файле, поэтому
+// неотправленные события переживают перезапуск процесса. При превышении лимита
+// размера удаляются самые старые сегменты.
+type Spool struct {
+	mu           sync.Mutex     // Мьютекс для синхронизации доступа к спулу.
+	dir          string         // Каталог спула.
+	maxBytes     int64          // Максимальный суммарный размер сегментов.
+	segmentBytes int64          // Размер сегмента, после которого начинается новый.
+	segments     []spoolSegment // Сегменты в порядке возрастания номера.
+	offset       int64          // Позиция чтения в первом сегменте.
+	active       *os.File       // Файл последнего сегмента, открытый на запись.
+	peekedSize   int64          // Размер записи, возвращённой последним Peek.
+}
+
+// OpenSpool открывает (или создаёт) спул в каталоге dir.
+//
+// @param dir каталог спула
+// @param maxBytes максимальный суммарный размер сегментов в байтах
+// @return указатель на Spool и ошибка (если есть)
+func OpenSpool(dir string, maxBytes int64) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога спула: %v", err)
+	}
+
+	segmentBytes := maxBytes / 8
+
+	if segmentBytes < 4096 {
+		segmentBytes = 4096
+	}
+
+	s := &Spool{
+		dir:          dir,
+		maxBytes:     maxBytes,
+		segmentBytes: segmentBytes,
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load восстанавливает состояние спула из файлов каталога.
+//
+// @return ошибка (если есть)
+func (s *Spool) load() error {
+	entries, err := os.ReadDir(s.dir)
+
+	if err != nil {
+		return fmt.Errorf("ошибка чтения каталога спула: %v", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		if entry.IsDir() || !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+
+		id, err := strconv.ParseInt(strings.TrimSuffix(name, spoolSegmentExt), 10, 64)
+
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+
+		if err != nil {
+			return fmt.Errorf("ошибка чтения сегмента спула: %v", err)
+		}
+
+		s.segments = append(s.segments, spoolSegment{id: id, size: info.Size()})
+	}
+
+	sort.Slice(s.segments, func(i, j int) bool {
+		return s.segments[i].id < s.segments[j].id
+	})
+
+	// Восстанавливаем позицию чтения
+	if data, err := os.ReadFile(filepath.Join(s.dir, spoolCursorFile)); err == nil {
+		var id, offset int64
+
+		if _, err := fmt.Sscanf(string(data), "%d %d", &id, &offset); err == nil {
+			for len(s.segments) > 0 && s.segments[0].id < id {
+				s.removeOldest()
+			}
+
+			if len(s.segments) > 0 && s.segments[0].id == id && offset <= s.segments[0].size {
+				s.offset = offset
+			}
+		}
+	}
+
+	if len(s.segments) == 0 {
+		return s.rotate()
+	}
+
+	// Отбрасываем недописанную запись в конце последнего сегмента
+	last := &s.segments[len(s.segments)-1]
+	path := s.segmentPath(last.id)
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return fmt.Errorf("ошибка чтения сегмента спула: %v", err)
+	}
+
+	if end := int64(bytes.LastIndexByte(data, '\n') + 1); end != last.size {
+		if err := os.Truncate(path, end); err != nil {
+			return fmt.Errorf("ошибка восстановления сегмента спула: %v", err)
+		}
+
+		last.size = end
+
+		if len(s.segments) == 1 && s.offset > end {
+			s.offset = end
+		}
+	}
+
+	s.active, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+
+	if err != nil {
+		return fmt.Errorf("ошибка открытия сегмента спула: %v", err)
+	}
+
+	return nil
+}
+
+// segmentPath возвращает путь к файлу сегмента.
+//
+// @param id номер сегмента
+// @return путь к файлу
+func (s *Spool) segmentPath(id int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", id, spoolSegmentExt))
+}
+
+// rotate закрывает текущий сегмент и начинает новый.
+//
+// @return ошибка (если есть)
+func (s *Spool) rotate() error {
+	var id int64
+
+	if len(s.segments) > 0 {
+		id = s.segments[len(s.segments)-1].id + 1
+	}
+
+	file, err := os.OpenFile(s.segmentPath(id), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+
+	if err != nil {
+		return fmt.Errorf("ошибка создания сегмента спула: %v", err)
+	}
+
+	if s.active != nil {
+		_ = s.active.Close()
+	}
+
+	s.active = file
+	s.segments = append(s.segments, spoolSegment{id: id})
+
+	return nil
+}
+
+// removeOldest удаляет самый старый сегмент и сбрасывает позицию чтения.
+func (s *Spool) removeOldest() {
+	if err := os.Remove(s.segmentPath(s.segments[0].id)); err != nil && !os.IsNotExist(err) {
+		log.Printf("ошибка удаления сегмента спула: %v", err)
+	}
+
+	s.segments = s.segments[1:]
+	s.offset = 0
+	s.peekedSize = 0
+}
+
+// totalSize возвращает суммарный размер всех сегментов.
+//
+// @return размер в байтах
+func (s *Spool) totalSize() int64 {
+	var total int64
+
+	for _, segment := range s.segments {
+		total += segment.size
+	}
+
+	return total
+}
+
+// Append дописывает сообщение в конец спула. Если после записи размер спула
+// превышает лимит, самые старые сегменты удаляются вместе с непрочитанными событиями.
+//
+// @param message указатель на сообщение
+// @return ошибка (если есть)
+func (s *Spool) Append(message *SentMessage) error {
+	data, err := json.Marshal(message)
+
+	if err != nil {
+		return fmt.Errorf("ошибка маршалинга события: %v", err)
+	}
+
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.segments[len(s.segments)-1].size >= s.segmentBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.active.Write(data); err != nil {
+		return fmt.Errorf("ошибка записи в спул: %v", err)
+	}
+
+	s.segments[len(s.segments)-1].size += int64(len(data))
+
+	if len(s.segments) == 1 || s.totalSize() <= s.maxBytes {
+		return nil
+	}
+
+	for len(s.segments) > 1 && s.totalSize() > s.maxBytes {
+		log.Printf("Спул переполнен, удалён самый старый сегмент с неотправленными событиями")
+		s.removeOldest()
+	}
+
+	return s.saveCursor()
+}
+
+// Peek возвращает самое старое неподтверждённое сообщение, не удаляя его.
+//
+// @return указатель на сообщение (nil, если спул пуст) и ошибка (если есть)
+func (s *Spool) Peek() (*SentMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		head := s.segments[0]
+
+		if s.offset >= head.size {
+			// Прочитанный сегмент удаляем, если он не последний
+			if len(s.segments) == 1 {
+				return nil, nil
+			}
+
+			s.removeOldest()
+
+			if err := s.saveCursor(); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		line, err := s.readLine(head.id, s.offset)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var message SentMessage
+
+		if err := json.Unmarshal(line, &message); err != nil {
+			log.Printf("Повреждённая запись в спуле пропущена: %v", err)
+			s.offset += int64(len(line))
+			continue
+		}
+
+		s.peekedSize = int64(len(line))
+		return &message, nil
+	}
+}
+
+// readLine читает одну запись сегмента начиная с позиции offset.
+//
+// @param id номер сегмента
+// @param offset позиция в сегменте
+// @return запись вместе с символом перевода строки и ошибка (если есть)
+func (s *Spool) readLine(id int64, offset int64) ([]byte, error) {
+	file, err := os.Open(s.segmentPath(id))
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия сегмента спула: %v", err)
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("ошибка чтения спула: %v", err)
+	}
+
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения спула: %v", err)
+	}
+
+	return line, nil
+}
+
+// Ack подтверждает отправку сообщения, полученного последним вызовом Peek,
+// и сохраняет новую позицию чтения на диск.
+//
+// @return ошибка (если есть)
+func (s *Spool) Ack() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.peekedSize == 0 {
+		return nil
+	}
+
+	s.offset += s.peekedSize
+	s.peekedSize = 0
+
+	return s.saveCursor()
+}
+
+// Position возвращает позицию сообщения, полученного последним вызовом Peek.
+//
+// @return позиция чтения
+func (s *Spool) Position() SpoolPosition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return SpoolPosition{Segment: s.segments[0].id, Offset: s.offset}
+}
+
+// End возвращает позицию, с которой будет записано следующее сообщение.
+//
+// @return позиция конца спула
+func (s *Spool) End() SpoolPosition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := s.segments[len(s.segments)-1]
+	return SpoolPosition{Segment: last.id, Offset: last.size}
+}
+
+// Empty сообщает, есть ли в спуле неподтверждённые сообщения.
+//
+// @return true, если спул пуст
+func (s *Spool) Empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.segments) == 1 && s.offset >= s.segments[0].size
+}
+
+// saveCursor атомарно сохраняет позицию чтения на диск.
+//
+// @return ошибка (если есть)
+func (s *Spool) saveCursor() error {
+	path := filepath.Join(s.dir, spoolCursorFile)
+	tmp := path + ".tmp"
+	data := fmt.Sprintf("%d %d", s.segments[0].id, s.offset)
+
+	if err := os.WriteFile(tmp, []byte(data), 0o600); err != nil {
+		return fmt.Errorf("ошибка сохранения позиции спула: %v", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("ошибка сохранения позиции спула: %v", err)
+	}
+
+	return nil
+}
+
+// Close закрывает файл текущего сегмента.
+func (s *Spool) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active != nil {
+		_ = s.active.Close()
+		s.active = nil
+	}
+}
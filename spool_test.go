@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openTestSpool открывает спул в каталоге dir и закрывает его по завершении теста.
+func openTestSpool(t *testing.T, dir string, maxBytes int64) *Spool {
+	t.Helper()
+
+	s, err := OpenSpool(dir, maxBytes)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// appendEvents дописывает в спул события с Id от first до last включительно.
+func appendEvents(t *testing.T, s *Spool, first int, last int) {
+	t.Helper()
+
+	for i := first; i <= last; i++ {
+		if err := s.Append(&SentMessage{Type: AddedDockerContainer, Id: fmt.Sprint(i), Data: "{}"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readIds читает и подтверждает все сообщения спула и возвращает их Id.
+func readIds(t *testing.T, s *Spool) []string {
+	t.Helper()
+
+	var ids []string
+
+	for {
+		message, err := s.Peek()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if message == nil {
+			return ids
+		}
+
+		ids = append(ids, message.Id)
+
+		if err := s.Ack(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSpoolRecoversCursor(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestSpool(t, dir, 1<<20)
+	appendEvents(t, s, 1, 3)
+
+	if _, err := s.Peek(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Ack(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Второе событие прочитано, но не подтверждено: после перезапуска оно должно прийти снова
+	if _, err := s.Peek(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Close()
+
+	reopened := openTestSpool(t, dir, 1<<20)
+
+	if ids := readIds(t, reopened); fmt.Sprint(ids) != "[2 3]" {
+		t.Fatalf("после перезапуска прочитаны события %v, ожидались [2 3]", ids)
+	}
+
+	if !reopened.Empty() {
+		t.Fatal("спул не пуст после чтения всех событий")
+	}
+}
+
+func TestSpoolTruncatesPartialRecord(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestSpool(t, dir, 1<<20)
+	appendEvents(t, s, 1, 2)
+	s.Close()
+
+	// Имитируем запись, прерванную падением процесса
+	segment := s.segmentPath(s.segments[len(s.segments)-1].id)
+	file, err := os.OpenFile(segment, os.O_WRONLY|os.O_APPEND, 0o600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = file.WriteString(`{"Type":2,"Id":"partial`)
+	_ = file.Close()
+
+	reopened := openTestSpool(t, dir, 1<<20)
+	appendEvents(t, reopened, 3, 3)
+
+	if ids := readIds(t, reopened); fmt.Sprint(ids) != "[1 2 3]" {
+		t.Fatalf("прочитаны события %v, ожидались [1 2 3]", ids)
+	}
+}
+
+func TestSpoolDropsOldestSegments(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestSpool(t, dir, 32*1024)
+	appendEvents(t, s, 1, 2000)
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+spoolSegmentExt))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.totalSize() > 32*1024 || len(segments) != len(s.segments) {
+		t.Fatalf("размер спула %d при лимите %d, сегментов на диске %d", s.totalSize(), 32*1024, len(segments))
+	}
+
+	ids := readIds(t, s)
+
+	if len(ids) == 0 || ids[0] == "1" || ids[len(ids)-1] != "2000" {
+		t.Fatalf("должны остаться только последние события, прочитаны %d с %v по %v", len(ids), ids[0], ids[len(ids)-1])
+	}
+
+	for i := 1; i < len(ids); i++ {
+		var prev, curr int
+		fmt.Sscan(ids[i-1], &prev)
+		fmt.Sscan(ids[i], &curr)
+
+		if curr != prev+1 {
+			t.Fatalf("нарушен порядок событий: %d после %d", curr, prev)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+)
+
+// terminalInputQueueSize задаёт число сообщений ExecInput, ожидающих записи в сессию.
+const terminalInputQueueSize = 256
+
+// terminal хранит входные потоки интерактивной exec-сессии.
+type terminal struct {
+	input  chan string       // Ввод, полученный из сообщений ExecInput.
+	resize chan TerminalSize // Последний запрошенный размер терминала.
+	done   chan struct{}     // Закрывается при завершении сессии.
+	buf    []byte            // Непрочитанный остаток последнего ввода.
+}
+
+// terminals хранит интерактивные exec-сессии по идентификатору запроса ExecContainer,
+// чтобы горутина чтения могла передавать им ввод и изменение размера терминала.
+type terminals struct {
+	mu       sync.Mutex           // Мьютекс для синхронизации доступа к sessions.
+	sessions map[string]*terminal // Сессии по идентификатору запроса.
+}
+
+// open регистрирует интерактивную сессию.
+// Вызывается из горутины чтения до запуска обработчика ExecContainer,
+// чтобы ввод и размер терминала, пришедшие раньше него, не были отброшены.
+//
+// @param id идентификатор запроса ExecContainer
+func (t *terminals) open(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.sessions == nil {
+		t.sessions = make(map[string]*terminal)
+	}
+
+	term := &terminal{
+		input:  make(chan string, terminalInputQueueSize),
+		resize: make(chan TerminalSize, 1),
+		done:   make(chan struct{}),
+	}
+	t.sessions[id] = term
+}
+
+// get возвращает интерактивную сессию по идентификатору запроса.
+//
+// @param id идентификатор запроса ExecContainer
+// @return указатель на terminal или nil
+func (t *terminals) get(id string) *terminal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.sessions[id]
+}
+
+// close завершает интерактивную сессию и удаляет её из реестра.
+//
+// @param id идентификатор запроса ExecContainer
+func (t *terminals) close(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if term, ok := t.sessions[id]; ok {
+		close(term.done)
+		delete(t.sessions, id)
+	}
+}
+
+// closeAll завершает все интерактивные сессии. Вызывается при разрыве соединения.
+func (t *terminals) closeAll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, term := range t.sessions {
+		close(term.done)
+		delete(t.sessions, id)
+	}
+}
+
+// deliver передаёт сообщение ExecInput или ExecResize сессии с тем же Id.
+// Вызывается из горутины чтения, поэтому никогда не блокируется.
+//
+// @param message указатель на входящее сообщение
+func (t *terminals) deliver(message *ReceiveMessage) {
+	t.mu.Lock()
+	term, ok := t.sessions[message.Id]
+	t.mu.Unlock()
+
+	if !ok {
+		log.Printf("Получен ввод для неизвестной exec-сессии: %s", message.Id)
+		return
+	}
+
+	if message.Type == ExecInput {
+		select {
+		case term.input <- message.Data:
+		default:
+			log.Printf("Очередь ввода exec-сессии %s переполнена, ввод отброшен", message.Id)
+		}
+
+		return
+	}
+
+	var size TerminalSize
+
+	if err := json.Unmarshal([]byte(message.Data), &size); err != nil {
+		log.Printf("Ошибка декодирования размера терминала: %v", err)
+		return
+	}
+
+	// Важен только последний размер, поэтому устаревший заменяется новым
+	select {
+	case <-term.resize:
+	default:
+	}
+
+	select {
+	case term.resize <- size:
+	default:
+	}
+}
+
+// reader возвращает поток ввода сессии. Поток завершается (io.EOF)
+// при отмене ctx или закрытии сессии.
+//
+// @param ctx контекст операции
+// @return io.Reader
+func (term *terminal) reader(ctx context.Context) io.Reader {
+	return &terminalReader{ctx: ctx, term: term}
+}
+
+// terminalReader читает ввод интерактивной сессии из сообщений ExecInput.
+type terminalReader struct {
+	ctx  context.Context // Контекст операции.
+	term *terminal       // Сессия, из которой читается ввод.
+}
+
+// Read читает очередную порцию ввода, ожидая её при необходимости.
+//
+// @param p буфер для чтения
+// @return число прочитанных байт и ошибка (io.EOF по завершении сессии)
+func (r *terminalReader) Read(p []byte) (int, error) {
+	for len(r.term.buf) == 0 {
+		select {
+		case data := <-r.term.input:
+			r.term.buf = []byte(data)
+		case <-r.term.done:
+			return 0, io.EOF
+		case <-r.ctx.Done():
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(p, r.term.buf)
+	r.term.buf = r.term.buf[n:]
+
+	return n, nil
+}
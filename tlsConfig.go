@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newDialer создает WebSocket-диалер с учётом TLS-настроек конфигурации.
+//
+// @param cfg конфигурация клиента
+// @return указатель на websocket.Dialer и ошибка (если есть)
+func newDialer(cfg *Config) (*websocket.Dialer, error) {
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 45 * time.Second,
+	}
+
+	if !cfg.UseTls {
+		return dialer, nil
+	}
+
+	tlsCfg, err := newTlsConfig(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dialer.TLSClientConfig = tlsCfg
+	return dialer, nil
+}
+
+// newTlsConfig формирует TLS-конфигурацию: доверенные корневые сертификаты,
+// клиентский сертификат для mTLS и проверку закреплённых ключей сервера.
+//
+// @param cfg конфигурация клиента
+// @return указатель на tls.Config и ошибка (если есть)
+func newTlsConfig(cfg *Config) (*tls.Config, error) {
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CaFile != "" {
+		pem, err := os.ReadFile(cfg.CaFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения CA-сертификатов: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("в файле %s не найдено ни одного сертификата", cfg.CaFile)
+		}
+
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, fmt.Errorf("для mTLS необходимо указать и CertFile, и KeyFile")
+		}
+
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("ошибка загрузки клиентского сертификата: %v", err)
+		}
+
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(cfg.PinnedSha256) > 0 {
+		pins := make(map[string]bool, len(cfg.PinnedSha256))
+
+		for _, pin := range cfg.PinnedSha256 {
+			pins[normalizeFingerprint(pin)] = true
+		}
+
+		tlsCfg.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPinnedKey(state, pins)
+		}
+	}
+
+	return tlsCfg, nil
+}
+
+// verifyPinnedKey проверяет, что открытый ключ сертификата сервера совпадает
+// с одним из закреплённых отпечатков.
+//
+// @param state состояние TLS-соединения
+// @param pins множество допустимых SHA-256 отпечатков ключа (SPKI) в hex
+// @return ошибка, если ни один отпечаток не совпал
+func verifyPinnedKey(state tls.ConnectionState, pins map[string]bool) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("сервер не предоставил сертификат")
+	}
+
+	sum := sha256.Sum256(state.PeerCertificates[0].RawSubjectPublicKeyInfo)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	if !pins[fingerprint] {
+		return fmt.Errorf("отпечаток ключа сервера %s не совпадает с закреплёнными", fingerprint)
+	}
+
+	return nil
+}
+
+// normalizeFingerprint приводит отпечаток к нижнему регистру и убирает разделители.
+//
+// @param fingerprint отпечаток в hex (допускаются двоеточия и пробелы)
+// @return нормализованный отпечаток
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.ToLower(fingerprint)
+	return strings.NewReplacer(":", "", " ", "").Replace(fingerprint)
+}
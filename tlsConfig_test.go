@@ -0,0 +1,209 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTlsServer запускает WebSocket-сервер с TLS на /ws.
+// При clientCAs != nil сервер требует клиентский сертификат, подписанный одним из них.
+func newTlsServer(t *testing.T, clientCAs *x509.CertPool) *httptest.Server {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+
+	if clientCAs != nil {
+		srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	}
+
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// writePem записывает DER-данные во временный PEM-файл и возвращает его путь.
+func writePem(t *testing.T, name string, blockType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// newClientCert создает самоподписанный клиентский сертификат и возвращает пути к сертификату и ключу.
+func newClientCert(t *testing.T) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return writePem(t, "client.pem", "CERTIFICATE", der), writePem(t, "client.key", "EC PRIVATE KEY", keyDer), cert
+}
+
+// tlsTestConfig возвращает конфигурацию для подключения к srv по wss с доверием к его сертификату.
+func tlsTestConfig(t *testing.T, srv *httptest.Server) *Config {
+	t.Helper()
+
+	return &Config{
+		Ip:       strings.TrimPrefix(srv.URL, "https://"),
+		UseTls:   true,
+		CaFile:   writePem(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw),
+		SpoolDir: t.TempDir(),
+	}
+}
+
+// connect подключает Communicator с конфигурацией cfg и возвращает ошибку подключения.
+func connect(t *testing.T, cfg *Config) error {
+	t.Helper()
+
+	c, err := NewCommunicator(cfg, NewFakeRuntime())
+
+	if err != nil {
+		return err
+	}
+
+	defer c.Close()
+
+	return c.Connect()
+}
+
+func TestConnectTlsWithCaFile(t *testing.T) {
+	srv := newTlsServer(t, nil)
+
+	if err := connect(t, tlsTestConfig(t, srv)); err != nil {
+		t.Fatalf("подключение с CaFile: %v", err)
+	}
+}
+
+func TestConnectTlsRejectsUnknownCa(t *testing.T) {
+	srv := newTlsServer(t, nil)
+	cfg := tlsTestConfig(t, srv)
+	cfg.CaFile = ""
+
+	if err := connect(t, cfg); err == nil {
+		t.Fatal("подключение к серверу с недоверенным сертификатом должно завершиться ошибкой")
+	}
+}
+
+func TestConnectMutualTls(t *testing.T) {
+	certFile, keyFile, cert := newClientCert(t)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	srv := newTlsServer(t, pool)
+
+	cfg := tlsTestConfig(t, srv)
+
+	if err := connect(t, cfg); err == nil {
+		t.Fatal("подключение без клиентского сертификата должно завершиться ошибкой")
+	}
+
+	cfg.CertFile = certFile
+	cfg.KeyFile = keyFile
+
+	if err := connect(t, cfg); err != nil {
+		t.Fatalf("подключение с клиентским сертификатом: %v", err)
+	}
+}
+
+func TestConnectPinnedKey(t *testing.T) {
+	srv := newTlsServer(t, nil)
+	sum := sha256.Sum256(srv.Certificate().RawSubjectPublicKeyInfo)
+	fingerprint := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	cfg := tlsTestConfig(t, srv)
+	cfg.PinnedSha256 = []string{strings.Repeat("00", sha256.Size)}
+
+	if err := connect(t, cfg); err == nil {
+		t.Fatal("подключение с несовпадающим отпечатком должно завершиться ошибкой")
+	}
+
+	// Отпечаток в верхнем регистре и с двоеточиями, как его показывает openssl
+	var parts []string
+
+	for i := 0; i < len(fingerprint); i += 2 {
+		parts = append(parts, fingerprint[i:i+2])
+	}
+
+	cfg.PinnedSha256 = []string{strings.Join(parts, ":")}
+
+	if err := connect(t, cfg); err != nil {
+		t.Fatalf("подключение с закреплённым отпечатком: %v", err)
+	}
+}
+
+func TestNewTlsConfigRequiresKeyPair(t *testing.T) {
+	certFile, _, _ := newClientCert(t)
+
+	if _, err := newTlsConfig(&Config{UseTls: true, CertFile: certFile}); err == nil {
+		t.Fatal("CertFile без KeyFile должен приводить к ошибке")
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// upload хранит состояние загрузки данных в контейнер.
+// Фрагменты записываются во временный файл прямо из горутины чтения,
+// поэтому быстрый отправитель не может переполнить память клиента.
+type upload struct {
+	mu       sync.Mutex    // Мьютекс для синхронизации доступа к состоянию.
+	file     *os.File      // Временный файл с принятыми данными.
+	sum      hash.Hash     // SHA-256 принятых данных.
+	size     int64         // Число принятых байт.
+	seq      int64         // Номер ожидаемого фрагмента.
+	err      error         // Ошибка приёма данных.
+	finished bool          // Приём данных завершён.
+	done     chan struct{} // Закрывается после последнего фрагмента или ошибки.
+	progress chan struct{} // Сигнал о принятом фрагменте.
+}
+
+// uploads хранит загрузки по идентификатору запроса CopyToContainer,
+// чтобы горутина чтения могла передавать им фрагменты CopyChunk.
+type uploads struct {
+	mu       sync.Mutex         // Мьютекс для синхронизации доступа к sessions.
+	sessions map[string]*upload // Загрузки по идентификатору запроса.
+}
+
+// open регистрирует загрузку и создаёт для неё временный файл.
+// Вызывается из горутины чтения до запуска обработчика CopyToContainer,
+// чтобы фрагменты, пришедшие раньше него, не были отброшены.
+// Ошибка создания файла сохраняется в загрузке и возвращается обработчику.
+//
+// @param id идентификатор запроса CopyToContainer
+func (u *uploads) open(id string) {
+	up := &upload{
+		sum:      sha256.New(),
+		done:     make(chan struct{}),
+		progress: make(chan struct{}, 1),
+	}
+
+	file, err := os.CreateTemp("", "copy-*")
+
+	if err != nil {
+		up.finish(fmt.Errorf("ошибка создания временного файла: %v", err))
+	} else {
+		up.file = file
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.sessions == nil {
+		u.sessions = make(map[string]*upload)
+	}
+
+	u.sessions[id] = up
+}
+
+// get возвращает загрузку по идентификатору запроса.
+//
+// @param id идентификатор запроса CopyToContainer
+// @return указатель на upload или nil
+func (u *uploads) get(id string) *upload {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.sessions[id]
+}
+
+// close удаляет загрузку из реестра и её временный файл.
+// Фрагменты, пришедшие после этого, отбрасываются.
+//
+// @param id идентификатор запроса CopyToContainer
+func (u *uploads) close(id string) {
+	u.mu.Lock()
+	up, ok := u.sessions[id]
+	delete(u.sessions, id)
+	u.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	up.mu.Lock()
+	defer up.mu.Unlock()
+
+	up.finish(nil)
+
+	if up.file != nil {
+		_ = up.file.Close()
+		_ = os.Remove(up.file.Name())
+	}
+}
+
+// closeAll прерывает все загрузки и удаляет их временные файлы. Вызывается при разрыве соединения.
+func (u *uploads) closeAll() {
+	u.mu.Lock()
+	ids := slices.Collect(maps.Keys(u.sessions))
+	u.mu.Unlock()
+
+	for _, id := range ids {
+		// Ожидающий обработчик должен получить ошибку, а не принять неполные данные
+		if up := u.get(id); up != nil {
+			up.mu.Lock()
+			up.finish(errSessionEnded)
+			up.mu.Unlock()
+		}
+
+		u.close(id)
+	}
+}
+
+// deliver передаёт фрагмент CopyChunk загрузке с тем же Id.
+// Вызывается из горутины чтения, поэтому не ждёт обработчика команды.
+//
+// @param message указатель на входящее сообщение
+func (u *uploads) deliver(message *ReceiveMessage) {
+	u.mu.Lock()
+	up, ok := u.sessions[message.Id]
+	u.mu.Unlock()
+
+	if !ok {
+		log.Printf("Получен фрагмент для неизвестной загрузки: %s", message.Id)
+		return
+	}
+
+	var chunk FileChunk
+	err := json.Unmarshal([]byte(message.Data), &chunk)
+
+	up.mu.Lock()
+	defer up.mu.Unlock()
+
+	if up.finished {
+		return
+	}
+
+	if err != nil {
+		up.finish(fmt.Errorf("ошибка декодирования фрагмента: %v", err))
+		return
+	}
+
+	if err := up.accept(&chunk); err != nil {
+		up.finish(err)
+		return
+	}
+
+	if chunk.Last {
+		up.finish(nil)
+		return
+	}
+
+	select {
+	case up.progress <- struct{}{}:
+	default:
+	}
+}
+
+// accept проверяет фрагмент и дописывает его во временный файл. Вызывается под мьютексом.
+//
+// @param chunk указатель на фрагмент
+// @return ошибка (если есть)
+func (up *upload) accept(chunk *FileChunk) error {
+	if chunk.Seq != up.seq {
+		return fmt.Errorf("нарушен порядок фрагментов: ожидался %d, получен %d", up.seq, chunk.Seq)
+	}
+
+	sum := sha256.Sum256(chunk.Data)
+
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), chunk.Sha256) {
+		return fmt.Errorf("неверная контрольная сумма фрагмента %d", chunk.Seq)
+	}
+
+	if up.size+int64(len(chunk.Data)) > copyUploadLimit {
+		return fmt.Errorf("объём данных превышает %d байт", copyUploadLimit)
+	}
+
+	if _, err := up.file.Write(chunk.Data); err != nil {
+		return fmt.Errorf("ошибка записи временного файла: %v", err)
+	}
+
+	up.sum.Write(chunk.Data)
+	up.size += int64(len(chunk.Data))
+	up.seq++
+
+	return nil
+}
+
+// finish завершает приём данных. Вызывается под мьютексом.
+//
+// @param err ошибка приёма (nil при успешном завершении)
+func (up *upload) finish(err error) {
+	if up.finished {
+		return
+	}
+
+	up.finished = true
+	up.err = err
+	close(up.done)
+}
+
+// result возвращает итог приёма данных.
+//
+// @return размер принятых данных, их SHA-256 в hex и ошибка приёма (если есть)
+func (up *upload) result() (int64, string, error) {
+	up.mu.Lock()
+	defer up.mu.Unlock()
+
+	return up.size, hex.EncodeToString(up.sum.Sum(nil)), up.err
+}
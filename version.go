@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// Version задаёт версию клиента. Переопределяется при сборке:
+// go build -ldflags "-X main.Version=1.2.3"
+var Version = "dev"
+
+// ProtocolVersion задаёт текущую версию протокола обмена с сервером.
+// Версия 1 — результаты команд передаются строкой, версия 2 — структурой CommandResult.
+const ProtocolVersion = 2
+
+// MinProtocolVersion задаёт минимальную версию протокола, которую поддерживает клиент.
+const MinProtocolVersion = 1
+
+// getAgentInfo собирает сведения о сборке клиента и системе, на которой он запущен.
+//
+// @return указатель на AgentInfo
+func getAgentInfo() *AgentInfo {
+	info := &AgentInfo{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		Os:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		info.Hostname = hostname
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+
+	if !ok {
+		return info
+	}
+
+	if info.Version == "dev" && buildInfo.Main.Version != "" && buildInfo.Main.Version != "(devel)" {
+		info.Version = buildInfo.Main.Version
+	}
+
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.BuildTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
+
+// getCapabilities формирует список возможностей клиента с учётом конфигурации.
+//
+// @param cfg конфигурация клиента
+// @param rt среда выполнения контейнеров
+// @param available доступна ли среда выполнения
+// @return указатель на Capabilities
+func getCapabilities(cfg *Config, rt ContainerRuntime, available bool) *Capabilities {
+	return &Capabilities{
+		Docker:   available,
+		Runtime:  rt.Name(),
+		Scripts:  !cfg.DisableScripts,
+		Commands: !cfg.DisableCommands,
+		Restart:  !cfg.DisableRestart,
+		Exec:     !cfg.DisableExec,
+		Features: []string{
+			"push-metrics",
+			"spool",
+			"request-id",
+			"structured-results",
+			"container-lifecycle",
+			"container-logs",
+			"cancel",
+			"container-exec",
+			"image-pull",
+			"container-create",
+			"compose",
+			"volumes",
+			"networks",
+			"disk-usage",
+			"prune",
+			"health-alerts",
+			"container-inspect",
+			"container-copy",
+			"container-update",
+		},
+	}
+}